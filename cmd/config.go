@@ -0,0 +1,18 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testTask/internal/config"
+)
+
+// printConfig loads the configuration the way serve does and writes the
+// effective settings, secrets redacted, with where each one came from.
+func printConfig(w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+	return cfg.Print(w)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testTask/internal/config"
+	"testTask/pkg/client/postgres"
+	"testTask/pkg/client/sqlite"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+)
+
+// database is the SQL backend selected with the storage setting.
+type database struct {
+	gorm    *gorm.DB
+	dialect goose.Dialect
+	// name labels the connection pool metrics.
+	name string
+
+	ping  func(ctx context.Context, timeout time.Duration) error
+	close func() error
+}
+
+func openDatabase(ctx context.Context, cfg *config.Config) (*database, error) {
+	var db *database
+	if cfg.Storage == config.StorageSQLite {
+		client, err := sqlite.NewClient(ctx, cfg.SQLite.Path)
+		if err != nil {
+			return nil, err
+		}
+		db = &database{
+			gorm:    client.DB,
+			dialect: goose.DialectSQLite3,
+			name:    filepath.Base(cfg.SQLite.Path),
+			ping:    client.Ping,
+			close:   client.Close,
+		}
+	} else {
+		client, err := postgres.NewClient(ctx, cfg.DB.DSN())
+		if err != nil {
+			return nil, err
+		}
+		db = &database{
+			gorm:    client.DB,
+			dialect: goose.DialectPostgres,
+			name:    cfg.DB.Name,
+			ping:    client.Ping,
+			close:   client.Close,
+		}
+	}
+
+	sqlDB, err := db.gorm.DB()
+	if err != nil {
+		_ = db.close()
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+	return db, nil
+}
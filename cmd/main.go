@@ -1,81 +1,52 @@
 package main
 
 import (
-	"context"
-	"errors"
-	"net/http"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
-	"testTask/internal/answer"
-	answerdb "testTask/internal/answer/db"
-	"testTask/internal/config"
-	"testTask/internal/question"
-	questiondb "testTask/internal/question/db"
-	"testTask/pkg/client/postgres"
+	"strings"
 	"testTask/pkg/logging"
-	"time"
 )
 
-func main() {
-	logger := logging.GetLogger()
+const usage = `Usage: app [command] [flags] [arguments]
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		logger.Fatalf("config error: %v", err)
-	}
+Commands:
+  serve [--auto-migrate]                run the HTTP server (default)
+  migrate [flags] up|down|status|redo   manage the database schema
+  config                                print the effective configuration
+  version                               print the build and schema versions
 
-	ctx := context.Background()
+serve, migrate and config accept --config FILE and a flag per setting;
+run "app config -h" to list them.
+`
 
-	client, err := postgres.NewClient(ctx, cfg.DSN)
-	if err != nil {
-		logger.Fatalf("postgres init error: %v", err)
+func main() {
+	// Without a command the binary serves, so `app --auto-migrate` works
+	// like `app serve --auto-migrate`.
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	defer func() {
-		if err := client.Close(); err != nil {
-			logger.Warnf("postgres close error: %v", err)
+	switch command {
+	case "serve":
+		serve(args)
+	case "migrate":
+		if err := migrate(os.Stdout, args); err != nil {
+			logging.GetLogger().Fatalf("migrate: %v", err)
 		}
-	}()
-
-	mux := http.NewServeMux()
-
-	questionStorage := questiondb.NewStorage(client.DB, logger)
-	questionService := question.NewService(questionStorage, logger)
-	questionHandler := question.NewHandler(logger, questionService)
-	questionHandler.Register(mux)
-
-	answerStorage := answerdb.NewStorage(client.DB, logger)
-	answerService := answer.NewService(answerStorage, logger)
-	answerHandler := answer.NewHandler(logger, answerService)
-	answerHandler.Register(mux)
-
-	startServer(mux)
-}
-
-func startServer(mux *http.ServeMux) {
-	logger := logging.GetLogger()
-
-	srv := &http.Server{Addr: os.Getenv("APP_PORT"), Handler: mux}
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Fatal("listen: %v", err)
+	case "config":
+		if err := printConfig(os.Stdout, args); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			os.Exit(1)
 		}
-	}()
-
-	logger.Info("server started")
-	<-ctx.Done()
-	logger.Info("server stopping...")
-
-	shCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(shCtx); err != nil {
-		logger.Fatalf("shutdown failed: %v", err)
+	case "version":
+		if err := printVersion(os.Stdout); err != nil {
+			logging.GetLogger().Fatalf("version: %v", err)
+		}
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
-
-	logger.Info("server stopped")
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testTask/internal/config"
+	"testTask/migrations"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// migrate runs a migration command against the database selected by the
+// configuration and reports what it did to w.
+func migrate(w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected one of up, down, status, redo")
+	}
+	if cfg.Storage == config.StorageMemory {
+		return errors.New("memory storage has no schema to migrate")
+	}
+
+	ctx := context.Background()
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.close()
+
+	sqlDB, err := db.gorm.DB()
+	if err != nil {
+		return err
+	}
+	provider, err := migrations.NewProvider(db.dialect, sqlDB)
+	if err != nil {
+		return err
+	}
+
+	return runMigration(ctx, w, provider, flags.Arg(0))
+}
+
+func runMigration(ctx context.Context, w io.Writer, provider *goose.Provider, command string) error {
+	switch command {
+	case "up":
+		results, err := provider.Up(ctx)
+		if err != nil {
+			return reportPartial(w, err)
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(w, "no pending migrations")
+		}
+		printResults(w, results...)
+
+	case "down":
+		result, err := provider.Down(ctx)
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			fmt.Fprintln(w, "no migrations to roll back")
+			return nil
+		}
+		if err != nil {
+			return reportPartial(w, err)
+		}
+		printResults(w, result)
+
+	case "redo":
+		down, err := provider.Down(ctx)
+		if err != nil {
+			return reportPartial(w, err)
+		}
+		printResults(w, down)
+		up, err := provider.ApplyVersion(ctx, down.Source.Version, true)
+		if err != nil {
+			return reportPartial(w, err)
+		}
+		printResults(w, up)
+
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, s := range statuses {
+			applied := "-"
+			if s.State == goose.StateApplied {
+				applied = s.AppliedAt.UTC().Format(time.DateTime)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, applied, s.Source.Path)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected one of up, down, status, redo", command)
+	}
+	return nil
+}
+
+// reportPartial prints the migrations applied before err stopped the run.
+func reportPartial(w io.Writer, err error) error {
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		printResults(w, partial.Applied...)
+		return partial.Err
+	}
+	return err
+}
+
+func printResults(w io.Writer, results ...*goose.MigrationResult) {
+	for _, r := range results {
+		fmt.Fprintln(w, r)
+	}
+}
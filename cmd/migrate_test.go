@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"testTask/migrations"
+	"testTask/pkg/client/sqlite"
+	"testTask/pkg/logging"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProvider(t *testing.T) *goose.Provider {
+	t.Helper()
+
+	client, err := sqlite.NewClient(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	db, err := client.DB.DB()
+	require.NoError(t, err)
+
+	provider, err := migrations.NewProvider(goose.DialectSQLite3, db)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestRunMigration(t *testing.T) {
+	ctx := context.Background()
+	provider := newProvider(t)
+	latest, err := migrations.Version()
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, runMigration(ctx, &out, provider, "down"))
+	assert.Equal(t, "no migrations to roll back\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runMigration(ctx, &out, provider, "up"))
+	assert.Contains(t, out.String(), "OK    up")
+	version, err := provider.GetDBVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	out.Reset()
+	require.NoError(t, runMigration(ctx, &out, provider, "redo"))
+	assert.Contains(t, out.String(), "OK    down")
+	assert.Contains(t, out.String(), "OK    up")
+	version, err = provider.GetDBVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	out.Reset()
+	require.NoError(t, runMigration(ctx, &out, provider, "down"))
+	out.Reset()
+	require.NoError(t, runMigration(ctx, &out, provider, "status"))
+	assert.Contains(t, out.String(), "VERSION")
+	assert.Contains(t, out.String(), "pending")
+
+	assert.Error(t, runMigration(ctx, &out, provider, "sideways"))
+}
+
+func TestEnsureSchema(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.GetLogger()
+	provider := newProvider(t)
+
+	err := ensureSchema(ctx, provider, false, logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--auto-migrate")
+
+	require.NoError(t, ensureSchema(ctx, provider, true, logger))
+	assert.NoError(t, ensureSchema(ctx, provider, false, logger))
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testTask/internal/answer"
+	answerdb "testTask/internal/answer/db"
+	"testTask/internal/auth"
+	"testTask/internal/comment"
+	commentdb "testTask/internal/comment/db"
+	"testTask/internal/config"
+	"testTask/internal/handlers"
+	"testTask/internal/health"
+	"testTask/internal/memory"
+	"testTask/internal/metrics"
+	"testTask/internal/question"
+	questiondb "testTask/internal/question/db"
+	"testTask/internal/search"
+	searchdb "testTask/internal/search/db"
+	"testTask/internal/tracing"
+	"testTask/internal/trash"
+	"testTask/internal/vote"
+	votedb "testTask/internal/vote/db"
+	"testTask/migrations"
+	"testTask/pkg/logging"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// serve runs the HTTP server until SIGINT or SIGTERM.
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	autoMigrate := flags.Bool("auto-migrate", false, "apply pending migrations before serving")
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		logging.GetLogger().Fatalf("config error: %v", err)
+	}
+
+	logger, err := logging.New(cfg.Log)
+	if err != nil {
+		logging.GetLogger().Fatalf("logger init error: %v", err)
+	}
+	logging.SetDefault(logger)
+	defer func() {
+		if err := logger.Close(); err != nil {
+			logger.Warnf("logger close error: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logger.Fatalf("tracing init error: %v", err)
+	}
+	defer func() {
+		shCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shCtx); err != nil {
+			logger.Warnf("tracing shutdown error: %v", err)
+		}
+	}()
+
+	var jwtVerifier *auth.JWTVerifier
+	if cfg.Auth.JWTSecret != "" {
+		jwtVerifier = auth.NewJWTVerifier(cfg.Auth.JWTSecret, cfg.Auth.JWTIssuer)
+	}
+	apiKeys, err := auth.ParseAPIKeys(cfg.Auth.APIKeys)
+	if err != nil {
+		logger.Fatalf("auth config error: %v", err)
+	}
+	if jwtVerifier == nil && apiKeys.Len() == 0 {
+		logger.Warn("neither auth.jwt_secret nor auth.api_keys is configured, all write requests will be rejected")
+	}
+	authn := auth.NewAuthenticator(jwtVerifier, apiKeys)
+
+	policy := auth.NewPolicy()
+
+	handlers.RegisterErrors(auth.ErrorMappings()...)
+	handlers.RegisterErrors(question.ErrorMappings()...)
+	handlers.RegisterErrors(answer.ErrorMappings()...)
+	handlers.RegisterErrors(comment.ErrorMappings()...)
+	handlers.RegisterErrors(vote.ErrorMappings()...)
+	handlers.RegisterErrors(search.ErrorMappings()...)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	var (
+		questionStorage question.Storage
+		answerStorage   answer.Storage
+		commentStorage  comment.Storage
+		voteStorage     vote.Storage
+		searchIndex     search.Index
+		checks          []health.Check
+		purgeable       = map[string]trash.Purgeable{}
+	)
+
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Warn("memory storage: data is lost on restart")
+		store := memory.NewStore()
+		questionStorage = store.Questions()
+		answerStorage = store.Answers()
+		commentStorage = store.Comments()
+		voteStorage = store.Votes()
+		searchIndex = store.Index()
+
+	default:
+		db, err := openDatabase(ctx, cfg)
+		if err != nil {
+			logger.Fatalf("%s init error: %v", cfg.Storage, err)
+		}
+
+		defer func() {
+			if err := db.close(); err != nil {
+				logger.Warnf("%s close error: %v", cfg.Storage, err)
+			}
+		}()
+
+		if err := db.gorm.Use(metrics.Plugin{}); err != nil {
+			logger.Fatalf("metrics plugin error: %v", err)
+		}
+		if err := db.gorm.Use(tracing.Plugin{}); err != nil {
+			logger.Fatalf("tracing plugin error: %v", err)
+		}
+		sqlDB, err := db.gorm.DB()
+		if err != nil {
+			logger.Fatalf("%s init error: %v", cfg.Storage, err)
+		}
+		if err := metrics.RegisterDBStats(sqlDB, db.name); err != nil {
+			logger.Fatalf("metrics init error: %v", err)
+		}
+
+		provider, err := migrations.NewProvider(db.dialect, sqlDB)
+		if err != nil {
+			logger.Fatalf("%s init error: %v", cfg.Storage, err)
+		}
+		if err := ensureSchema(ctx, provider, *autoMigrate, logger); err != nil {
+			logger.Fatalf("schema error: %v", err)
+		}
+		checks = append(checks,
+			health.Check{Name: cfg.Storage, Check: func(ctx context.Context) error {
+				return db.ping(ctx, cfg.HTTP.ReadinessTimeout)
+			}},
+			health.Migrations(provider),
+		)
+
+		questionStorage = questiondb.NewStorage(db.gorm, logger)
+		answerStorage = answerdb.NewStorage(db.gorm, logger)
+		commentStorage = commentdb.NewStorage(db.gorm, logger)
+		voteStorage = votedb.NewStorage(db.gorm, logger)
+		searchIndex = searchdb.NewIndex(db.gorm, logger)
+	}
+
+	if cfg.Features.Comments {
+		commentService := comment.NewService(commentStorage, policy, logger)
+		commentHandler := comment.NewHandler(logger, commentService)
+		commentHandler.Register(mux)
+		purgeable["comments"] = commentService
+	}
+
+	if cfg.Features.Votes {
+		voteService := vote.NewService(voteStorage, logger)
+		voteHandler := vote.NewHandler(logger, voteService)
+		voteHandler.Register(mux)
+	}
+
+	if cfg.Features.Search {
+		searchService := search.NewService(searchIndex, logger)
+		searchHandler := search.NewHandler(logger, searchService)
+		searchHandler.Register(mux)
+	}
+
+	healthHandler := health.NewHandler(cfg.HTTP.ReadinessTimeout, checks...)
+	healthHandler.Register(mux)
+
+	questionService := question.NewService(questionStorage, policy, logger)
+	questionHandler := question.NewHandler(logger, questionService)
+	questionHandler.Register(mux)
+	purgeable["questions"] = questionService
+
+	answerService := answer.NewService(answerStorage, question.NewAnswerLookup(questionStorage), policy, logger)
+	answerHandler := answer.NewHandler(logger, answerService)
+	answerHandler.Register(mux)
+	purgeable["answers"] = answerService
+
+	purger := trash.NewPurger(cfg.TrashRetention, cfg.TrashPurgeInterval, logger, purgeable)
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+	go purger.Run(jobsCtx)
+
+	if cfg.Features.Archive {
+		archiver := question.NewArchiver(questionService, cfg.ArchiveAfter, cfg.ArchiveInterval, logger)
+		go archiver.Run(jobsCtx)
+	}
+
+	var handler http.Handler = mux
+	handler = auth.Middleware(authn, logger)(handler)
+	handler = handlers.RequestLogger(mux, logger)(handler)
+	handler = tracing.Middleware(mux)(handler)
+	handler = metrics.Middleware(mux)(handler)
+
+	startServer(handler, healthHandler, cfg.HTTP)
+}
+
+// ensureSchema applies pending migrations when autoMigrate is set, and
+// otherwise refuses a schema older than the one this build expects.
+func ensureSchema(ctx context.Context, provider *goose.Provider, autoMigrate bool, logger *logging.Logger) error {
+	if autoMigrate {
+		results, err := provider.Up(ctx)
+		for _, r := range results {
+			logger.Infof("applied migration %s in %s", r.Source.Path, r.Duration)
+		}
+		if err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
+		return nil
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	pending, err := provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	if pending {
+		return fmt.Errorf("database schema is at version %d but this build expects %d: "+
+			"run `migrate up` or start with --auto-migrate", current, target)
+	}
+	if current > target {
+		logger.Warnf("database schema version %d is newer than %d known to this build", current, target)
+	}
+	return nil
+}
+
+func startServer(handler http.Handler, probes *health.Handler, cfg config.HTTPConfig) {
+	logger := logging.GetLogger()
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalf("listen: %v", err)
+		}
+	}()
+
+	logger.Infof("server started on %s", cfg.Addr)
+	<-ctx.Done()
+	logger.Info("server stopping...")
+
+	// Fail readiness first and keep serving for a while so load balancers
+	// stop routing new requests here before connections are closed.
+	probes.Drain()
+	time.Sleep(cfg.ShutdownDrain)
+
+	shCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shCtx); err != nil {
+		logger.Fatalf("shutdown failed: %v", err)
+	}
+
+	logger.Info("server stopped")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"testTask/migrations"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// printVersion writes the build version, the VCS revision recorded by the
+// Go toolchain and the schema version the build expects.
+func printVersion(w io.Writer) error {
+	schema, err := migrations.Version()
+	if err != nil {
+		return err
+	}
+
+	revision, goVersion := "unknown", "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		goVersion = info.GoVersion
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.modified":
+				if s.Value == "true" {
+					revision += "-dirty"
+				}
+			}
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "version:  %s\nrevision: %s\ngo:       %s\nschema:   %d\n",
+		version, revision, goVersion, schema)
+	return err
+}
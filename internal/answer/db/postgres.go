@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"testTask/internal/answer"
+	"testTask/internal/comment"
+	"testTask/internal/trash"
 	"testTask/pkg/logging"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -20,45 +23,265 @@ func NewStorage(db *gorm.DB, logger *logging.Logger) answer.Storage {
 }
 
 func (r *repository) Create(ctx context.Context, a *answer.Answer) (*answer.Answer, error) {
-	if err := r.db.WithContext(ctx).Create(a).Error; err != nil {
-		r.logger.Errorf("failed to create answer: %v", err)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		return tx.Create(&answer.Revision{AnswerID: a.ID, Text: a.Text, AuthorID: a.UserID}).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, answer.ErrAlreadyAnswered
+	}
+	// The question was purged after the service looked it up.
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return nil, answer.ErrQuestionNotFound
+	}
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to create answer: %v", err)
 		return nil, fmt.Errorf("create answer: %w", err)
 	}
 	return a, nil
 }
 
+// AcceptedExpr tells whether an answer is the accepted one of its question.
+// Queries selecting answers.* add it as the accepted column.
+const AcceptedExpr = "EXISTS (SELECT 1 FROM questions WHERE questions.accepted_answer_id = answers.id)"
+
+// withAccepted selects answers together with their accepted flag.
+func (r *repository) withAccepted(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&answer.Answer{}).
+		Select("answers.*, " + AcceptedExpr + " AS accepted")
+}
+
 func (r *repository) FindOne(ctx context.Context, id uint) (*answer.Answer, error) {
 	var a answer.Answer
-	if err := r.db.WithContext(ctx).First(&a, id).Error; err != nil {
+	if err := r.withAccepted(ctx).First(&a, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		r.logger.Errorf("failed to find answer id=%d: %v", id, err)
+		r.logger.Ctx(ctx).Errorf("failed to find answer id=%d: %v", id, err)
 		return nil, fmt.Errorf("find answer: %w", err)
 	}
 	return &a, nil
 }
 
+func (r *repository) HasAnswered(ctx context.Context, questionID uint, userID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&answer.Answer{}).
+		Where("question_id = ? AND user_id = ?", questionID, userID).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to check answers of user %q to question id=%d: %v", userID, questionID, err)
+		return false, fmt.Errorf("check answers: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *repository) Update(ctx context.Context, a *answer.Answer, authorID string) (*answer.Answer, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(a).Update("text", a.Text).Error; err != nil {
+			return err
+		}
+		return tx.Create(&answer.Revision{AnswerID: a.ID, Text: a.Text, AuthorID: authorID}).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to update answer id=%d: %v", a.ID, err)
+		return nil, fmt.Errorf("update answer: %w", err)
+	}
+	return a, nil
+}
+
 func (r *repository) Delete(ctx context.Context, id uint) error {
-	if err := r.db.WithContext(ctx).Delete(&answer.Answer{}, id).Error; err != nil {
-		r.logger.Errorf("failed to delete answer id=%d: %v", id, err)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("questions").
+			Where("accepted_answer_id = ?", id).
+			UpdateColumn("accepted_answer_id", nil).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&answer.Answer{}).
+			Where("id = ?", id).
+			UpdateColumn("deleted_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		// Comments share the answer's deleted_at so Restore brings back
+		// only those deleted with it.
+		return tx.Model(&comment.Comment{}).
+			Where("answer_id = ?", id).
+			UpdateColumn("deleted_at", now).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to delete answer id=%d: %v", id, err)
 		return fmt.Errorf("delete answer: %w", err)
 	}
 	return nil
 }
 
-func (r *repository) FindByQuestionAndUser(ctx context.Context, questionID uint, userID string) (*answer.Answer, error) {
+func (r *repository) FindByQuestion(ctx context.Context, questionID uint, filter answer.ListFilter) ([]answer.Answer, error) {
+	var list []answer.Answer
+
+	query := r.withAccepted(ctx).Where("question_id = ?", questionID)
+	if filter.ExcludeID != 0 {
+		query = query.Where("id <> ?", filter.ExcludeID)
+	}
+
+	order := "created_at ASC, id ASC"
+	if filter.Sort == answer.SortScore {
+		order = "score DESC, id ASC"
+		if after := filter.After; after != nil {
+			query = query.Where("(score < ? OR (score = ? AND id > ?))", after.Score, after.Score, after.ID)
+		}
+	} else if after := filter.After; after != nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", after.Time, after.Time, after.ID)
+	}
+
+	if err := query.
+		Order(order).
+		Limit(filter.Limit).
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list answers for question_id=%d: %v", questionID, err)
+		return nil, fmt.Errorf("list answers: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) FindAccepted(ctx context.Context, questionID uint) (*answer.Answer, error) {
 	var a answer.Answer
+
+	if err := r.withAccepted(ctx).
+		Where("id = (SELECT accepted_answer_id FROM questions WHERE questions.id = ?)", questionID).
+		Take(&a).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find accepted answer of question id=%d: %v", questionID, err)
+		return nil, fmt.Errorf("find accepted answer: %w", err)
+	}
+
+	return &a, nil
+}
+
+func (r *repository) FindRevisions(ctx context.Context, answerID uint) ([]answer.Revision, error) {
+	var list []answer.Revision
+
 	if err := r.db.WithContext(ctx).
-		Where("question_id = ? AND user_id = ?", questionID, userID).
-		First(&a).Error; err != nil {
+		Where("answer_id = ?", answerID).
+		Order("id ASC").
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list revisions of answer id=%d: %v", answerID, err)
+		return nil, fmt.Errorf("list answer revisions: %w", err)
+	}
+
+	return list, nil
+}
 
+func (r *repository) FindRevision(ctx context.Context, answerID, revisionID uint) (*answer.Revision, error) {
+	var rev answer.Revision
+
+	if err := r.db.WithContext(ctx).
+		Where("answer_id = ?", answerID).
+		First(&rev, revisionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
+		r.logger.Ctx(ctx).Errorf("failed to find revision id=%d of answer id=%d: %v", revisionID, answerID, err)
+		return nil, fmt.Errorf("find answer revision: %w", err)
+	}
+
+	return &rev, nil
+}
 
-		r.logger.Errorf("failed to find answer by question_id=%d and user_id=%s: %v", questionID, userID, err)
-		return nil, fmt.Errorf("find answer by question and user: %w", err)
+func (r *repository) FindDeleted(ctx context.Context, filter trash.Filter) ([]answer.Answer, error) {
+	var list []answer.Answer
+
+	query := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if after := filter.After; after != nil {
+		query = query.Where("(deleted_at < ? OR (deleted_at = ? AND id < ?))", after.DeletedAt, after.DeletedAt, after.ID)
+	}
+
+	if err := query.
+		Order("deleted_at DESC, id DESC").
+		Limit(filter.Limit).
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list deleted answers: %v", err)
+		return nil, fmt.Errorf("list deleted answers: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) FindOneDeleted(ctx context.Context, id uint) (*answer.Answer, error) {
+	var a answer.Answer
+
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&a, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find deleted answer id=%d: %v", id, err)
+		return nil, fmt.Errorf("find deleted answer: %w", err)
 	}
+
 	return &a, nil
 }
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var live int64
+		if err := tx.Table("questions").
+			Where("id = (SELECT question_id FROM answers WHERE id = ?) AND deleted_at IS NULL", id).
+			Count(&live).Error; err != nil {
+			return err
+		}
+		if live == 0 {
+			return answer.ErrQuestionDeleted
+		}
+
+		var a answer.Answer
+		if err := tx.Unscoped().First(&a, id).Error; err != nil {
+			return err
+		}
+		if !a.DeletedAt.Valid {
+			return nil
+		}
+
+		if err := tx.Unscoped().Model(&comment.Comment{}).
+			Where("answer_id = ? AND deleted_at = ?", id, a.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&a).UpdateColumn("deleted_at", nil).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return answer.ErrAlreadyAnswered
+	}
+	if err != nil {
+		if errors.Is(err, answer.ErrQuestionDeleted) {
+			return err
+		}
+		r.logger.Ctx(ctx).Errorf("failed to restore answer id=%d: %v", id, err)
+		return fmt.Errorf("restore answer: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&answer.Answer{})
+	if res.Error != nil {
+		r.logger.Ctx(ctx).Errorf("failed to purge deleted answers: %v", res.Error)
+		return 0, fmt.Errorf("purge answers: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
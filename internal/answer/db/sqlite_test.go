@@ -0,0 +1,22 @@
+package db_test
+
+import (
+	"testing"
+
+	"testTask/internal/answer"
+	answerdb "testTask/internal/answer/db"
+	"testTask/internal/question"
+	questiondb "testTask/internal/question/db"
+	"testTask/internal/storagetest"
+	"testTask/pkg/logging"
+)
+
+func newSQLiteStorages(t *testing.T) (question.Storage, answer.Storage) {
+	db := storagetest.SQLite(t)
+	logger := logging.GetLogger()
+	return questiondb.NewStorage(db, logger), answerdb.NewStorage(db, logger)
+}
+
+func TestAnswerStorageSQLite(t *testing.T) {
+	storagetest.TestAnswerStorage(t, newSQLiteStorages)
+}
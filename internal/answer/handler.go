@@ -1,13 +1,30 @@
 package answer
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 	"testTask/internal/handlers"
+	"testTask/internal/pagination"
+	"testTask/internal/trash"
 	"testTask/pkg/logging"
 )
 
+// ErrorMappings tells handlers.Error how to report answer errors.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrNotFound, Status: http.StatusNotFound, Code: "answer_not_found"},
+		{Err: ErrRevisionNotFound, Status: http.StatusNotFound, Code: "revision_not_found"},
+		{Err: ErrAlreadyAnswered, Status: http.StatusConflict, Code: "already_answered"},
+		{Err: ErrNotEditable, Status: http.StatusConflict, Code: "answer_not_editable"},
+		{Err: ErrQuestionNotFound, Status: http.StatusNotFound, Code: "question_not_found"},
+		{Err: ErrQuestionClosed, Status: http.StatusConflict, Code: "question_closed"},
+		{Err: ErrQuestionLocked, Status: http.StatusConflict, Code: "question_locked"},
+		{Err: ErrQuestionDeleted, Status: http.StatusConflict, Code: "question_deleted"},
+		{Err: ErrInvalidQuestion, Status: http.StatusBadRequest, Code: "invalid_question", Field: "question_id"},
+		{Err: ErrInvalidSort, Status: http.StatusBadRequest, Code: "invalid_sort", Field: "sort"},
+	}
+}
+
 type handler struct {
 	logger  *logging.Logger
 	service Service
@@ -22,84 +39,206 @@ func NewHandler(logger *logging.Logger, service Service) handlers.Handler {
 
 func (h *handler) Register(router *http.ServeMux) {
 	router.HandleFunc("GET /answers/{id}", h.GetById)
+	router.HandleFunc("GET /questions/{id}/answers/", h.List)
 	router.HandleFunc("POST /questions/{id}/answers/", h.Create)
+	router.HandleFunc("PUT /answers/{id}", h.Update)
+	router.HandleFunc("PATCH /answers/{id}", h.Update)
 	router.HandleFunc("DELETE /answers/{id}", h.Delete)
+	router.HandleFunc("POST /answers/{id}/restore", h.Restore)
+	router.HandleFunc("GET /admin/trash/answers", h.Trash)
+	router.HandleFunc("GET /answers/{id}/revisions", h.Revisions)
+	router.HandleFunc("POST /answers/{id}/revisions/{revisionId}/rollback", h.Rollback)
 }
 
 func (h *handler) GetById(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	idUint, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil || idUint == 0 {
-		handlers.WriteError(w, http.StatusBadRequest, "invalid id")
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
-	ans, err := h.service.GetByID(r.Context(), uint(idUint))
+	ans, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			handlers.WriteError(w, http.StatusNotFound, err.Error())
-			return
-		}
-		h.logger.Errorf("get answer error: %v", err)
-		handlers.WriteError(w, http.StatusInternalServerError, "internal error")
+		handlers.Error(w, r, err)
 		return
 	}
 
 	handlers.WriteJSON(w, http.StatusOK, ans)
 }
 
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	questionID, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.ListByQuestion(r.Context(), questionID, params)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, page)
+}
+
 func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
-	qidStr := r.PathValue("id")
-	qidUint, err := strconv.ParseUint(qidStr, 10, 64)
-	if err != nil || qidUint == 0 {
-		handlers.WriteError(w, http.StatusBadRequest, "invalid question id")
+	questionID, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
 	var req CreateAnswerRequest
 	if err := handlers.ReadJSON(r, &req); err != nil {
-		h.logger.Errorf("failed to decode create answer request: %v", err)
-		handlers.WriteError(w, http.StatusBadRequest, "invalid JSON body")
+		handlers.Error(w, r, err)
 		return
 	}
 	defer func() {
 		if err := r.Body.Close(); err != nil {
-			h.logger.Warnf("failed to close request body: %v", err)
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
 		}
 	}()
 
-	req.QuestionID = uint(qidUint)
+	req.QuestionID = questionID
 
 	ans, err := h.service.Create(r.Context(), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrEmptyText),
-			errors.Is(err, ErrEmptyUserID),
-			errors.Is(err, ErrInvalidQuestion):
-			handlers.WriteError(w, http.StatusBadRequest, err.Error())
-		default:
-			h.logger.Errorf("create answer error: %v", err)
-			handlers.WriteError(w, http.StatusInternalServerError, "internal error")
-		}
+		handlers.Error(w, r, err)
 		return
 	}
 
 	handlers.WriteJSON(w, http.StatusCreated, ans)
 }
 
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	var req UpdateAnswerRequest
+	if err := handlers.ReadJSON(r, &req); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+		}
+	}()
+
+	ans, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, ans)
+}
+
+func (h *handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	ans, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, ans)
+}
+
+func (h *handler) Trash(w http.ResponseWriter, r *http.Request) {
+	params, err := trash.ParseParams(r.URL.Query())
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.Trash(r.Context(), params)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *handler) Revisions(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	list, err := h.service.Revisions(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	revisionID, err := handlers.PathID(r, "revisionId")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	ans, err := h.service.Rollback(r.Context(), id, revisionID)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, ans)
+}
+
 func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	idUint, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil || idUint == 0 {
-		handlers.WriteError(w, http.StatusBadRequest, "invalid id")
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), uint(idUint)); err != nil {
-		h.logger.Errorf("delete answer error: %v", err)
-		handlers.WriteError(w, http.StatusInternalServerError, "internal error")
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func parseListParams(r *http.Request) (ListParams, error) {
+	params := ListParams{
+		Cursor: r.URL.Query().Get("cursor"),
+		Sort:   SortField(r.URL.Query().Get("sort")),
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return params, pagination.ErrInvalidLimit
+		}
+		params.Limit = n
+	}
+	return params, nil
+}
@@ -0,0 +1,22 @@
+package answer
+
+import "context"
+
+// QuestionLookup is the part of the question package the answer service
+// depends on. It is implemented there, so this package does not have to
+// import it.
+type QuestionLookup interface {
+	// LookupQuestion returns ErrQuestionNotFound unless id is a live
+	// question.
+	LookupQuestion(ctx context.Context, id uint) (*QuestionInfo, error)
+}
+
+// QuestionInfo is what answers need to know about their question.
+type QuestionInfo struct {
+	ID           uint
+	AnswerPolicy Policy
+	// Closed questions take no new answers. Locked ones take none either
+	// and also freeze the answers they already have.
+	Closed bool
+	Locked bool
+}
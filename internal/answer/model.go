@@ -2,6 +2,8 @@ package answer
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Answer struct {
@@ -10,10 +12,95 @@ type Answer struct {
 	UserID     string    `gorm:"type:varchar(64);not null;" json:"user_id"`
 	Text       string    `gorm:"type:text;not null" json:"text"`
 	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"`
+
+	// Score is the sum of votes, kept up to date by the vote storage.
+	Score int64 `gorm:"not null;default:0" json:"score"`
+
+	// Accepted reports whether the question author accepted this answer.
+	Accepted bool `gorm:"->" json:"accepted"`
+
+	// Exclusive is set for answers given under a single-answer policy; a
+	// user holds at most one live exclusive answer per question.
+	Exclusive bool `gorm:"not null" json:"-"`
+}
+
+// Policy says how many answers a user may give to a question and whether
+// they can be edited.
+type Policy string
+
+const (
+	// PolicySingle allows one answer per user that cannot be edited.
+	PolicySingle Policy = "single"
+	// PolicySingleEditable allows one answer per user that can be edited.
+	PolicySingleEditable Policy = "single_editable"
+	// PolicyMultiple allows any number of answers per user.
+	PolicyMultiple Policy = "multiple"
+)
+
+// DefaultPolicy applies to questions created without an explicit policy.
+const DefaultPolicy = PolicySingleEditable
+
+// Exclusive reports whether answers given under p count towards the
+// one-answer-per-user limit.
+func (p Policy) Exclusive() bool {
+	return p != PolicyMultiple
 }
 
 type CreateAnswerRequest struct {
 	QuestionID uint   `json:"question_id" validate:"required"`
-	UserID     string `json:"user_id" validate:"required"`
-	Text       string `json:"text" validate:"required"`
+	Text       string `json:"text" validate:"required,max=10000"`
+}
+
+type UpdateAnswerRequest struct {
+	Text string `json:"text" validate:"required,max=10000"`
+}
+
+// Revision is one stored version of an answer's text. The first revision
+// is written on create, every edit or rollback appends another.
+type Revision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AnswerID  uint      `gorm:"not null;index" json:"answer_id"`
+	Text      string    `gorm:"type:text;not null" json:"text"`
+	AuthorID  string    `gorm:"type:varchar(64);not null" json:"author_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Revision) TableName() string {
+	return "answer_revisions"
+}
+
+type SortField string
+
+const (
+	// SortCreatedAt lists answers oldest first.
+	SortCreatedAt SortField = "created_at"
+	// SortScore lists answers by score, highest first.
+	SortScore SortField = "score"
+)
+
+type ListParams struct {
+	Limit  int
+	Cursor string
+	Sort   SortField
+}
+
+// ListFilter is the resolved form of ListParams handed to Storage.
+type ListFilter struct {
+	Limit int
+	Sort  SortField
+	After *Position
+
+	// ExcludeID leaves out one answer, the accepted one pinned in front.
+	ExcludeID uint
+}
+
+// Position is a decoded keyset cursor over (created_at, id), or over
+// (score, id) when sorting by score.
+type Position struct {
+	Time  time.Time
+	Score int64
+	ID    uint
 }
@@ -3,79 +3,137 @@ package answer
 import (
 	"context"
 	"errors"
+	"strconv"
 	"strings"
+	"time"
 
+	"testTask/internal/auth"
+	"testTask/internal/metrics"
+	"testTask/internal/pagination"
+	"testTask/internal/tracing"
+	"testTask/internal/trash"
+	"testTask/internal/validation"
 	"testTask/pkg/logging"
 )
 
 var (
-	ErrEmptyText       = errors.New("answer text is empty")
-	ErrEmptyUserID     = errors.New("user id is empty")
 	ErrInvalidQuestion = errors.New("question id is invalid")
 	ErrNotFound        = errors.New("answer not found")
+	ErrInvalidSort     = errors.New("invalid sort field")
 	ErrAlreadyAnswered = errors.New("user has already answered this question")
+	ErrNotEditable     = errors.New("answers to this question cannot be edited")
+
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrQuestionClosed   = errors.New("question is closed")
+	ErrQuestionLocked   = errors.New("question is locked")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrQuestionDeleted  = errors.New("question of this answer is deleted")
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateAnswerRequest) (*Answer, error)
 	GetByID(ctx context.Context, id uint) (*Answer, error)
+	ListByQuestion(ctx context.Context, questionID uint, params ListParams) (*pagination.Page[Answer], error)
+	Update(ctx context.Context, id uint, req *UpdateAnswerRequest) (*Answer, error)
 	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (*Answer, error)
+	Trash(ctx context.Context, params trash.Params) (*pagination.Page[Answer], error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	Revisions(ctx context.Context, id uint) ([]Revision, error)
+	Rollback(ctx context.Context, id, revisionID uint) (*Answer, error)
 }
 
 type service struct {
-	storage Storage
-	logger  *logging.Logger
+	storage   Storage
+	questions QuestionLookup
+	policy    auth.Policy
+	logger    *logging.Logger
 }
 
-func NewService(storage Storage, logger *logging.Logger) Service {
+func NewService(storage Storage, questions QuestionLookup, policy auth.Policy, logger *logging.Logger) Service {
 	return &service{
-		storage: storage,
-		logger:  logger,
+		storage:   storage,
+		questions: questions,
+		policy:    policy,
+		logger:    logger,
 	}
 }
 
-func (s *service) Create(ctx context.Context, req *CreateAnswerRequest) (*Answer, error) {
-	text := strings.TrimSpace(req.Text)
-	userID := strings.TrimSpace(req.UserID)
+func (s *service) Create(ctx context.Context, req *CreateAnswerRequest) (_ *Answer, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Create")
+	defer tracing.End(span, &err)
 
-	if req.QuestionID == 0 {
-		return nil, ErrInvalidQuestion
-	}
-	if userID == "" {
-		return nil, ErrEmptyUserID
+	author, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if text == "" {
-		return nil, ErrEmptyText
+
+	if err := validation.Struct(req); err != nil {
+		return nil, err
 	}
 
-	existed, err := s.storage.FindByQuestionAndUser(ctx, req.QuestionID, userID)
+	text := strings.TrimSpace(req.Text)
+	userID := author.UserID
+
+	q, err := s.lookupQuestion(ctx, req.QuestionID)
 	if err != nil {
-		s.logger.Errorf("failed to check existing answer: %v", err)
 		return nil, err
 	}
-	if existed != nil {
-		return nil, ErrAlreadyAnswered
+	if q.Locked {
+		metrics.AnswersRejected.WithLabelValues(metrics.RejectQuestionLocked).Inc()
+		return nil, ErrQuestionLocked
+	}
+	if q.Closed {
+		metrics.AnswersRejected.WithLabelValues(metrics.RejectQuestionClosed).Inc()
+		return nil, ErrQuestionClosed
 	}
 
+	// A single answer can be neither edited nor replaced, so one in the
+	// trash still counts. Trashed answers were live first, so the unique
+	// index below has already stopped any race to this point.
+	if q.AnswerPolicy == PolicySingle {
+		answered, err := s.storage.HasAnswered(ctx, q.ID, userID)
+		if err != nil {
+			s.logger.Ctx(ctx).Errorf("failed to check answers to question id=%d: %v", q.ID, err)
+			return nil, err
+		}
+		if answered {
+			metrics.AnswersRejected.WithLabelValues(metrics.RejectDuplicate).Inc()
+			return nil, ErrAlreadyAnswered
+		}
+	}
+
+	// The limit itself is enforced by a unique index, so concurrent
+	// requests cannot both slip past a check made here.
 	a := &Answer{
 		QuestionID: req.QuestionID,
 		UserID:     userID,
 		Text:       text,
+		Exclusive:  q.AnswerPolicy.Exclusive(),
 	}
 
 	created, err := s.storage.Create(ctx, a)
 	if err != nil {
-		s.logger.Errorf("failed to create answer: %v", err)
+		if errors.Is(err, ErrAlreadyAnswered) {
+			metrics.AnswersRejected.WithLabelValues(metrics.RejectDuplicate).Inc()
+		} else {
+			s.logger.Ctx(ctx).Errorf("failed to create answer: %v", err)
+		}
 		return nil, err
 	}
+	metrics.AnswersCreated.Inc()
 
 	return created, nil
 }
 
-func (s *service) GetByID(ctx context.Context, id uint) (*Answer, error) {
+func (s *service) GetByID(ctx context.Context, id uint) (_ *Answer, err error) {
+	ctx, span := tracing.Start(ctx, "answer.GetByID")
+	defer tracing.End(span, &err)
+
 	a, err := s.storage.FindOne(ctx, id)
 	if err != nil {
-		s.logger.Errorf("failed to get answer id=%d: %v", id, err)
+		s.logger.Ctx(ctx).Errorf("failed to get answer id=%d: %v", id, err)
 		return nil, err
 	}
 	if a == nil {
@@ -84,10 +142,339 @@ func (s *service) GetByID(ctx context.Context, id uint) (*Answer, error) {
 	return a, nil
 }
 
-func (s *service) Delete(ctx context.Context, id uint) error {
+func (s *service) Update(ctx context.Context, id uint, req *UpdateAnswerRequest) (_ *Answer, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Update")
+	defer tracing.End(span, &err)
+
+	editor, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+
+	a, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(editor, auth.ActionEdit, a.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkEditable(ctx, a); err != nil {
+		return nil, err
+	}
+	if a.Text == text {
+		return a, nil
+	}
+
+	a.Text = text
+
+	updated, err := s.storage.Update(ctx, a, editor.UserID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to update answer id=%d: %v", id, err)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *service) Revisions(ctx context.Context, id uint) (_ []Revision, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Revisions")
+	defer tracing.End(span, &err)
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.FindRevisions(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list revisions of answer id=%d: %v", id, err)
+		return nil, err
+	}
+	if list == nil {
+		list = []Revision{}
+	}
+	return list, nil
+}
+
+func (s *service) Rollback(ctx context.Context, id, revisionID uint) (_ *Answer, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Rollback")
+	defer tracing.End(span, &err)
+
+	editor, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(editor, auth.ActionEdit, a.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkEditable(ctx, a); err != nil {
+		return nil, err
+	}
+
+	rev, err := s.storage.FindRevision(ctx, id, revisionID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get revision id=%d of answer id=%d: %v", revisionID, id, err)
+		return nil, err
+	}
+	if rev == nil {
+		return nil, ErrRevisionNotFound
+	}
+	if a.Text == rev.Text {
+		return a, nil
+	}
+
+	a.Text = rev.Text
+
+	updated, err := s.storage.Update(ctx, a, editor.UserID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to roll back answer id=%d to revision id=%d: %v", id, revisionID, err)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// lookupQuestion returns the live question an answer is given to.
+func (s *service) lookupQuestion(ctx context.Context, id uint) (*QuestionInfo, error) {
+	q, err := s.questions.LookupQuestion(ctx, id)
+	if err != nil {
+		if !errors.Is(err, ErrQuestionNotFound) {
+			s.logger.Ctx(ctx).Errorf("failed to look up question id=%d: %v", id, err)
+		}
+		return nil, err
+	}
+	return q, nil
+}
+
+// checkEditable returns an error when the question of a no longer lets its
+// answers change: it is locked, or its policy forbids edits.
+func (s *service) checkEditable(ctx context.Context, a *Answer) error {
+	q, err := s.lookupQuestion(ctx, a.QuestionID)
+	if err != nil {
+		return err
+	}
+	if q.Locked {
+		return ErrQuestionLocked
+	}
+	if q.AnswerPolicy == PolicySingle {
+		return ErrNotEditable
+	}
+	return nil
+}
+
+func (s *service) ListByQuestion(ctx context.Context, questionID uint, params ListParams) (_ *pagination.Page[Answer], err error) {
+	ctx, span := tracing.Start(ctx, "answer.ListByQuestion")
+	defer tracing.End(span, &err)
+
+	if questionID == 0 {
+		return nil, ErrInvalidQuestion
+	}
+
+	key, value := cursorKey, createdAtValue
+	switch params.Sort {
+	case "":
+		params.Sort = SortCreatedAt
+	case SortCreatedAt:
+	case SortScore:
+		key, value = scoreCursorKey, scoreValue
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	filter, err := resolveListParams(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.lookupQuestion(ctx, questionID); err != nil {
+		return nil, err
+	}
+
+	// The accepted answer is pinned on top of the first page and left
+	// out of the keyset listing, so it does not count towards the limit.
+	accepted, err := s.storage.FindAccepted(ctx, questionID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get accepted answer for question id=%d: %v", questionID, err)
+		return nil, err
+	}
+	if accepted != nil {
+		filter.ExcludeID = accepted.ID
+	}
+
+	// Ask for one extra row to learn whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+
+	list, err := s.storage.FindByQuestion(ctx, questionID, filter)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list answers for question id=%d: %v", questionID, err)
+		return nil, err
+	}
+
+	page := newPage(list, limit, key, value)
+	if accepted != nil && filter.After == nil {
+		page.Items = append([]Answer{*accepted}, page.Items...)
+	}
+	return page, nil
+}
+
+func (s *service) Restore(ctx context.Context, id uint) (_ *Answer, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Restore")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.storage.FindOneDeleted(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get deleted answer id=%d: %v", id, err)
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	if err := s.policy.Authorize(caller, auth.ActionRestore, a.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Restore(ctx, id); err != nil {
+		if !errors.Is(err, ErrQuestionDeleted) && !errors.Is(err, ErrAlreadyAnswered) {
+			s.logger.Ctx(ctx).Errorf("failed to restore answer id=%d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) Trash(ctx context.Context, params trash.Params) (_ *pagination.Page[Answer], err error) {
+	ctx, span := tracing.Start(ctx, "answer.Trash")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionViewTrash, ""); err != nil {
+		return nil, err
+	}
+
+	filter, err := trash.Resolve(params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.FindDeleted(ctx, filter)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list deleted answers: %v", err)
+		return nil, err
+	}
+
+	return trash.NewPage(list, filter, trashPosition), nil
+}
+
+func (s *service) Purge(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "answer.Purge")
+	defer tracing.End(span, &err)
+
+	n, err := s.storage.Purge(ctx, before)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to purge deleted answers: %v", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "answer.Delete")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	a, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionDelete, a.UserID); err != nil {
+		return err
+	}
+
 	if err := s.storage.Delete(ctx, id); err != nil {
-		s.logger.Errorf("failed to delete answer id=%d: %v", id, err)
+		s.logger.Ctx(ctx).Errorf("failed to delete answer id=%d: %v", id, err)
 		return err
 	}
 	return nil
 }
+
+const (
+	cursorKey      = "created_at:asc"
+	scoreCursorKey = "score:desc"
+)
+
+func resolveListParams(p ListParams, key string) (ListFilter, error) {
+	limit, err := pagination.Limit(p.Limit)
+	if err != nil {
+		return ListFilter{}, err
+	}
+
+	f := ListFilter{Limit: limit, Sort: p.Sort}
+
+	c, err := pagination.Decode(p.Cursor, key)
+	if err != nil {
+		return ListFilter{}, err
+	}
+	if c != nil {
+		f.After = &Position{ID: c.ID}
+		if key == scoreCursorKey {
+			f.After.Score, err = strconv.ParseInt(c.Value, 10, 64)
+		} else {
+			f.After.Time, err = time.Parse(time.RFC3339Nano, c.Value)
+		}
+		if err != nil {
+			return ListFilter{}, pagination.ErrInvalidCursor
+		}
+	}
+
+	return f, nil
+}
+
+// newPage trims the extra row fetched past limit and, if there was one,
+// issues a cursor pointing at the last row kept.
+func newPage(list []Answer, limit int, key string, value func(Answer) string) *pagination.Page[Answer] {
+	page := &pagination.Page[Answer]{Items: list}
+	if page.Items == nil {
+		page.Items = []Answer{}
+	}
+	if len(list) > limit {
+		page.Items = list[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = pagination.Cursor{
+			Key:   key,
+			Value: value(last),
+			ID:    last.ID,
+		}.Encode()
+	}
+	return page
+}
+
+func createdAtValue(a Answer) string { return a.CreatedAt.UTC().Format(time.RFC3339Nano) }
+
+func trashPosition(a Answer) trash.Position {
+	return trash.Position{DeletedAt: a.DeletedAt.Time, ID: a.ID}
+}
+
+func scoreValue(a Answer) string { return strconv.FormatInt(a.Score, 10) }
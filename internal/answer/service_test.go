@@ -3,8 +3,14 @@ package answer
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"testTask/internal/auth"
+	"testTask/internal/pagination"
+	"testTask/internal/trash"
+	"testTask/internal/validation"
 	"testTask/pkg/logging"
 
 	"github.com/stretchr/testify/assert"
@@ -32,97 +38,216 @@ func (m *mockStorage) FindOne(ctx context.Context, id uint) (*Answer, error) {
 	return nil, args.Error(1)
 }
 
+func (m *mockStorage) HasAnswered(ctx context.Context, questionID uint, userID string) (bool, error) {
+	args := m.Called(ctx, questionID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockStorage) Update(ctx context.Context, a *Answer, authorID string) (*Answer, error) {
+	args := m.Called(ctx, a, authorID)
+	if v := args.Get(0); v != nil {
+		return v.(*Answer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockStorage) Delete(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func newTestService(t *testing.T) (*service, *mockStorage) {
+func (m *mockStorage) FindDeleted(ctx context.Context, filter trash.Filter) ([]Answer, error) {
+	args := m.Called(ctx, filter)
+	if v := args.Get(0); v != nil {
+		return v.([]Answer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) FindOneDeleted(ctx context.Context, id uint) (*Answer, error) {
+	args := m.Called(ctx, id)
+	if v := args.Get(0); v != nil {
+		return v.(*Answer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockStorage) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockStorage) FindRevisions(ctx context.Context, answerID uint) ([]Revision, error) {
+	args := m.Called(ctx, answerID)
+	if v := args.Get(0); v != nil {
+		return v.([]Revision), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) FindRevision(ctx context.Context, answerID, revisionID uint) (*Revision, error) {
+	args := m.Called(ctx, answerID, revisionID)
+	if v := args.Get(0); v != nil {
+		return v.(*Revision), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockQuestions struct {
+	mock.Mock
+}
+
+func (m *mockQuestions) LookupQuestion(ctx context.Context, id uint) (*QuestionInfo, error) {
+	args := m.Called(ctx, id)
+	if v := args.Get(0); v != nil {
+		return v.(*QuestionInfo), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// openQuestion answers lookups of id with a live open question.
+func openQuestion(m *mockQuestions, id uint, policy Policy) {
+	m.On("LookupQuestion", mock.Anything, id).
+		Return(&QuestionInfo{ID: id, AnswerPolicy: policy}, nil)
+}
+
+func (m *mockStorage) FindByQuestion(ctx context.Context, questionID uint, filter ListFilter) ([]Answer, error) {
+	args := m.Called(ctx, questionID, filter)
+	if v := args.Get(0); v != nil {
+		return v.([]Answer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) FindAccepted(ctx context.Context, questionID uint) (*Answer, error) {
+	args := m.Called(ctx, questionID)
+	if v := args.Get(0); v != nil {
+		return v.(*Answer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newTestService(t *testing.T) (*service, *mockStorage, *mockQuestions) {
 	t.Helper()
 
 	logger := logging.GetLogger()
 	storage := &mockStorage{}
+	questions := &mockQuestions{}
 
 	svc := &service{
-		storage: storage,
-		logger:  logger,
+		storage:   storage,
+		questions: questions,
+		policy:    auth.NewPolicy(),
+		logger:    logger,
 	}
 
-	return svc, storage
+	return svc, storage, questions
+}
+
+func withUser(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleUser})
+}
+
+func withModerator(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleModerator})
 }
 
 func TestService_Create_InvalidQuestionID(t *testing.T) {
-	svc, storage := newTestService(t)
-	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
 
 	req := &CreateAnswerRequest{
 		QuestionID: 0,
-		UserID:     "jh24h5",
 		Text:       "test",
 	}
 
 	a, err := svc.Create(ctx, req)
 
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, ErrInvalidQuestion))
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, []validation.FieldError{{Field: "question_id", Code: "required", Message: "is required"}}, ve.Fields)
 	assert.Nil(t, a)
 
 	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
-func TestService_Create_EmptyUserID(t *testing.T) {
-	svc, storage := newTestService(t)
+func TestService_Create_Unauthenticated(t *testing.T) {
+	svc, storage, _ := newTestService(t)
 	ctx := context.Background()
 
 	req := &CreateAnswerRequest{
 		QuestionID: 1,
-		UserID:     "  ",
 		Text:       "test",
 	}
 
 	a, err := svc.Create(ctx, req)
 
 	require.Error(t, err)
-	assert.True(t, errors.Is(err, ErrEmptyUserID))
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
 	assert.Nil(t, a)
 
 	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestService_Create_EmptyText(t *testing.T) {
-	svc, storage := newTestService(t)
-	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
 
 	req := &CreateAnswerRequest{
 		QuestionID: 1,
-		UserID:     "jh24h5",
 		Text:       "   ",
 	}
 
 	a, err := svc.Create(ctx, req)
 
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, ErrEmptyText))
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Fields, 1)
+	assert.Equal(t, "text", ve.Fields[0].Field)
+	assert.Equal(t, "required", ve.Fields[0].Code)
 	assert.Nil(t, a)
 
 	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
+func TestService_Create_ReportsAllFieldErrors(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	_, err := svc.Create(ctx, &CreateAnswerRequest{Text: strings.Repeat("ы", 10001)})
+
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Fields, 2)
+	assert.Equal(t, "question_id", ve.Fields[0].Field)
+	assert.Equal(t, "text", ve.Fields[1].Field)
+	assert.Equal(t, "max", ve.Fields[1].Code)
+
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestService_Create_OK(t *testing.T) {
-	svc, storage := newTestService(t)
-	ctx := context.Background()
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
 
 	req := &CreateAnswerRequest{
 		QuestionID: 10,
-		UserID:     "  jh24h5  ",
 		Text:       "  test text  ",
 	}
 
+	openQuestion(questions, uint(10), PolicySingleEditable)
+
 	storage.
 		On("Create", mock.Anything, mock.MatchedBy(func(a *Answer) bool {
 			return a.QuestionID == 10 &&
-				a.UserID == "jh24h5" && // проверяем, что trim сработал
-				a.Text == "test text"
+				a.UserID == "jh24h5" && // автор берётся из контекста, а не из тела запроса
+				a.Text == "test text" &&
+				a.Exclusive
 		})).
 		Return(&Answer{
 			ID:         1,
@@ -143,8 +268,135 @@ func TestService_Create_OK(t *testing.T) {
 	storage.AssertExpectations(t)
 }
 
+func TestService_Create_AlreadyAnswered(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	openQuestion(questions, uint(10), PolicySingleEditable)
+	storage.
+		On("Create", mock.Anything, mock.Anything).
+		Return((*Answer)(nil), ErrAlreadyAnswered)
+
+	a, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 10, Text: "again"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrAlreadyAnswered))
+	assert.Nil(t, a)
+	storage.AssertNotCalled(t, "HasAnswered", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Create_SingleCountsDeletedAnswers(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	openQuestion(questions, uint(10), PolicySingle)
+	storage.
+		On("HasAnswered", mock.Anything, uint(10), "jh24h5").
+		Return(true, nil)
+
+	a, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 10, Text: "after deleting the first one"})
+
+	require.ErrorIs(t, err, ErrAlreadyAnswered)
+	assert.Nil(t, a)
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_SingleFirstAnswer(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	openQuestion(questions, uint(10), PolicySingle)
+	storage.
+		On("HasAnswered", mock.Anything, uint(10), "jh24h5").
+		Return(false, nil)
+	storage.
+		On("Create", mock.Anything, mock.MatchedBy(func(a *Answer) bool { return a.Exclusive })).
+		Return(&Answer{ID: 1, QuestionID: 10, UserID: "jh24h5", Text: "first"}, nil)
+
+	_, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 10, Text: "first"})
+
+	require.NoError(t, err)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Create_MultiplePolicy(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	openQuestion(questions, uint(10), PolicyMultiple)
+	storage.
+		On("Create", mock.Anything, mock.MatchedBy(func(a *Answer) bool {
+			return !a.Exclusive
+		})).
+		Return(&Answer{ID: 2, QuestionID: 10, UserID: "jh24h5", Text: "one more"}, nil)
+
+	_, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 10, Text: "one more"})
+
+	require.NoError(t, err)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Create_QuestionNotFound(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	questions.
+		On("LookupQuestion", mock.Anything, uint(999)).
+		Return(nil, ErrQuestionNotFound)
+
+	_, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 999, Text: "text"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrQuestionNotFound))
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_QuestionClosed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		info QuestionInfo
+		want error
+	}{
+		{"closed", QuestionInfo{ID: 10, Closed: true}, ErrQuestionClosed},
+		{"locked", QuestionInfo{ID: 10, Locked: true}, ErrQuestionLocked},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, storage, questions := newTestService(t)
+			ctx := withUser("jh24h5")
+
+			questions.
+				On("LookupQuestion", mock.Anything, uint(10)).
+				Return(&tc.info, nil)
+
+			_, err := svc.Create(ctx, &CreateAnswerRequest{QuestionID: 10, Text: "late"})
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.want))
+			storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_Update_QuestionLocked(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5", Text: "old"}, nil)
+	questions.
+		On("LookupQuestion", mock.Anything, uint(10)).
+		Return(&QuestionInfo{ID: 10, AnswerPolicy: PolicyMultiple, Locked: true}, nil)
+
+	_, err := svc.Update(ctx, 5, &UpdateAnswerRequest{Text: "new"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrQuestionLocked))
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestService_GetByID_NotFound(t *testing.T) {
-	svc, storage := newTestService(t)
+	svc, storage, _ := newTestService(t)
 	ctx := context.Background()
 
 	storage.
@@ -161,7 +413,7 @@ func TestService_GetByID_NotFound(t *testing.T) {
 }
 
 func TestService_GetByID_StorageError(t *testing.T) {
-	svc, storage := newTestService(t)
+	svc, storage, _ := newTestService(t)
 	ctx := context.Background()
 
 	storageErr := errors.New("db is down")
@@ -180,7 +432,7 @@ func TestService_GetByID_StorageError(t *testing.T) {
 }
 
 func TestService_GetByID_OK(t *testing.T) {
-	svc, storage := newTestService(t)
+	svc, storage, _ := newTestService(t)
 	ctx := context.Background()
 
 	expected := &Answer{
@@ -204,9 +456,12 @@ func TestService_GetByID_OK(t *testing.T) {
 }
 
 func TestService_Delete_OK(t *testing.T) {
-	svc, storage := newTestService(t)
-	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
 
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5"}, nil)
 	storage.
 		On("Delete", mock.Anything, uint(5)).
 		Return(nil)
@@ -217,10 +472,28 @@ func TestService_Delete_OK(t *testing.T) {
 	storage.AssertExpectations(t)
 }
 
+func TestService_Delete_NotFound(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOne", mock.Anything, uint(404)).
+		Return(nil, nil)
+
+	err := svc.Delete(ctx, 404)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
 func TestService_Delete_Error(t *testing.T) {
-	svc, storage := newTestService(t)
-	ctx := context.Background()
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
 
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5"}, nil)
 	delErr := errors.New("cannot delete")
 
 	storage.
@@ -234,3 +507,328 @@ func TestService_Delete_Error(t *testing.T) {
 
 	storage.AssertExpectations(t)
 }
+
+func TestService_ListByQuestion_InvalidQuestionID(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.ListByQuestion(ctx, 0, ListParams{})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidQuestion))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindByQuestion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ListByQuestion_QuestionNotFound(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+
+	questions.On("LookupQuestion", mock.Anything, uint(999)).Return(nil, ErrQuestionNotFound)
+
+	page, err := svc.ListByQuestion(context.Background(), 999, ListParams{})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrQuestionNotFound))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindByQuestion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ListByQuestion_Pages(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := context.Background()
+	openQuestion(questions, 10, PolicyMultiple)
+
+	created := time.Date(2025, 11, 14, 10, 0, 0, 0, time.UTC)
+	rows := []Answer{
+		{ID: 1, QuestionID: 10, CreatedAt: created},
+		{ID: 2, QuestionID: 10, CreatedAt: created},
+		{ID: 3, QuestionID: 10, CreatedAt: created.Add(time.Minute)},
+	}
+
+	storage.On("FindAccepted", mock.Anything, uint(10)).Return(nil, nil)
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.Limit == 3 && f.After == nil
+		})).
+		Return(rows, nil).
+		Once()
+
+	page, err := svc.ListByQuestion(ctx, 10, ListParams{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.After != nil && f.After.ID == 2 && f.After.Time.Equal(created)
+		})).
+		Return(rows[2:], nil).
+		Once()
+
+	page, err = svc.ListByQuestion(ctx, 10, ListParams{Limit: 2, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_ListByQuestion_InvalidCursor(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.ListByQuestion(ctx, 10, ListParams{Cursor: "not-a-cursor"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pagination.ErrInvalidCursor))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindByQuestion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ListByQuestion_ByScore(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := context.Background()
+	openQuestion(questions, 10, PolicyMultiple)
+
+	rows := []Answer{
+		{ID: 4, QuestionID: 10, Score: 7},
+		{ID: 2, QuestionID: 10, Score: 3},
+		{ID: 9, QuestionID: 10, Score: 3},
+	}
+
+	storage.On("FindAccepted", mock.Anything, uint(10)).Return(nil, nil)
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.Sort == SortScore && f.After == nil
+		})).
+		Return(rows, nil).
+		Once()
+
+	page, err := svc.ListByQuestion(ctx, 10, ListParams{Limit: 2, Sort: SortScore})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.Sort == SortScore && f.After != nil && f.After.Score == 3 && f.After.ID == 2
+		})).
+		Return(rows[2:], nil).
+		Once()
+
+	page, err = svc.ListByQuestion(ctx, 10, ListParams{Limit: 2, Sort: SortScore, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	// A score cursor is not valid for the default order.
+	_, err = svc.ListByQuestion(ctx, 10, ListParams{Cursor: pagination.Cursor{Key: scoreCursorKey, Value: "3", ID: 2}.Encode()})
+	assert.True(t, errors.Is(err, pagination.ErrInvalidCursor))
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_ListByQuestion_AcceptedPinned(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := context.Background()
+	openQuestion(questions, 10, PolicyMultiple)
+
+	created := time.Date(2025, 11, 14, 10, 0, 0, 0, time.UTC)
+	accepted := &Answer{ID: 7, QuestionID: 10, CreatedAt: created.Add(time.Hour), Accepted: true}
+	rows := []Answer{
+		{ID: 1, QuestionID: 10, CreatedAt: created},
+		{ID: 2, QuestionID: 10, CreatedAt: created.Add(time.Minute)},
+		{ID: 3, QuestionID: 10, CreatedAt: created.Add(2 * time.Minute)},
+	}
+
+	storage.On("FindAccepted", mock.Anything, uint(10)).Return(accepted, nil)
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.ExcludeID == 7 && f.After == nil
+		})).
+		Return(rows, nil).
+		Once()
+
+	page, err := svc.ListByQuestion(ctx, 10, ListParams{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, uint(7), page.Items[0].ID)
+	assert.True(t, page.Items[0].Accepted)
+	assert.Equal(t, uint(2), page.Items[2].ID)
+
+	storage.
+		On("FindByQuestion", mock.Anything, uint(10), mock.MatchedBy(func(f ListFilter) bool {
+			return f.ExcludeID == 7 && f.After != nil && f.After.ID == 2
+		})).
+		Return(rows[2:], nil).
+		Once()
+
+	page, err = svc.ListByQuestion(ctx, 10, ListParams{Limit: 2, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, uint(3), page.Items[0].ID)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_ListByQuestion_InvalidSort(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+
+	_, err := svc.ListByQuestion(context.Background(), 10, ListParams{Sort: "votes"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidSort))
+	storage.AssertNotCalled(t, "FindByQuestion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Update_OK(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5", Text: "old"}, nil)
+	openQuestion(questions, uint(10), PolicySingleEditable)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(a *Answer) bool {
+			return a.ID == 5 && a.Text == "new"
+		}), "jh24h5").
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5", Text: "new"}, nil)
+
+	a, err := svc.Update(ctx, 5, &UpdateAnswerRequest{Text: "new"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "new", a.Text)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_NotEditable(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5", Text: "old"}, nil)
+	openQuestion(questions, uint(10), PolicySingle)
+
+	a, err := svc.Update(ctx, 5, &UpdateAnswerRequest{Text: "new"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotEditable))
+	assert.Nil(t, a)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Rollback_RevisionNotFound(t *testing.T) {
+	svc, storage, questions := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5", Text: "current"}, nil)
+	openQuestion(questions, uint(10), PolicyMultiple)
+	storage.
+		On("FindRevision", mock.Anything, uint(5), uint(2)).
+		Return((*Revision)(nil), nil)
+
+	a, err := svc.Rollback(ctx, 5, 2)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRevisionNotFound))
+	assert.Nil(t, a)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Restore_QuestionDeleted(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withModerator("mod")
+
+	storage.
+		On("FindOneDeleted", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5"}, nil)
+	storage.
+		On("Restore", mock.Anything, uint(5)).
+		Return(ErrQuestionDeleted)
+
+	a, err := svc.Restore(ctx, 5)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrQuestionDeleted))
+	assert.Nil(t, a)
+
+	storage.AssertNotCalled(t, "FindOne", mock.Anything, mock.Anything)
+}
+
+func TestService_Restore_NotInTrash(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOneDeleted", mock.Anything, uint(5)).
+		Return((*Answer)(nil), nil)
+
+	a, err := svc.Restore(ctx, 5)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, a)
+
+	storage.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+func TestService_Restore_AuthorForbidden(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("jh24h5")
+
+	storage.
+		On("FindOneDeleted", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5"}, nil)
+
+	a, err := svc.Restore(ctx, 5)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	assert.Nil(t, a)
+
+	storage.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_Forbidden(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := withUser("someone-else")
+
+	storage.
+		On("FindOne", mock.Anything, uint(5)).
+		Return(&Answer{ID: 5, QuestionID: 10, UserID: "jh24h5"}, nil)
+
+	err := svc.Delete(ctx, 5)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_Unauthenticated(t *testing.T) {
+	svc, storage, _ := newTestService(t)
+	ctx := context.Background()
+
+	err := svc.Delete(ctx, 5)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+
+	storage.AssertNotCalled(t, "FindOne", mock.Anything, mock.Anything)
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
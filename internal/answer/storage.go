@@ -1,10 +1,35 @@
 package answer
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"testTask/internal/trash"
+)
 
 type Storage interface {
+	// Create returns ErrAlreadyAnswered when a.Exclusive is set and the
+	// user already has a live exclusive answer to the question.
 	Create(ctx context.Context, a *Answer) (*Answer, error)
 	FindOne(ctx context.Context, id uint) (*Answer, error)
+	// HasAnswered reports whether the user has an answer to the question,
+	// live or in the trash.
+	HasAnswered(ctx context.Context, questionID uint, userID string) (bool, error)
+	Update(ctx context.Context, a *Answer, authorID string) (*Answer, error)
+	// Delete soft-deletes the answer and withdraws its acceptance.
 	Delete(ctx context.Context, id uint) error
-	FindByQuestionAndUser(ctx context.Context, questionID uint, userID string) (*Answer, error)
+	FindDeleted(ctx context.Context, filter trash.Filter) ([]Answer, error)
+	FindOneDeleted(ctx context.Context, id uint) (*Answer, error)
+	// Restore returns ErrQuestionDeleted while the parent question is in
+	// the trash; the question has to be restored first. Like Create, it
+	// returns ErrAlreadyAnswered if the answer would break the limit.
+	Restore(ctx context.Context, id uint) error
+	// Purge hard-deletes answers that were soft-deleted before the given
+	// time and returns how many were removed.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	FindByQuestion(ctx context.Context, questionID uint, filter ListFilter) ([]Answer, error)
+	// FindAccepted returns the accepted answer of a question, or nil.
+	FindAccepted(ctx context.Context, questionID uint) (*Answer, error)
+	FindRevisions(ctx context.Context, answerID uint) ([]Revision, error)
+	FindRevision(ctx context.Context, answerID, revisionID uint) (*Revision, error)
 }
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// APIKeys is a static set of keys, each bound to one principal.
+type APIKeys struct {
+	keys map[[sha256.Size]byte]*Principal
+}
+
+// ParseAPIKeys reads a comma separated list of key=user_id[:role] entries.
+func ParseAPIKeys(spec string) (*APIKeys, error) {
+	k := &APIKeys{keys: make(map[[sha256.Size]byte]*Principal)}
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, user, ok := strings.Cut(pair, "=")
+		user, roleName, _ := strings.Cut(user, ":")
+		key, user = strings.TrimSpace(key), strings.TrimSpace(user)
+		if !ok || key == "" || user == "" {
+			return nil, fmt.Errorf("invalid api key entry %q, want key=user_id[:role]", pair)
+		}
+
+		role, err := ParseRole(strings.TrimSpace(roleName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid api key entry %q: %w", pair, err)
+		}
+
+		k.keys[sha256.Sum256([]byte(key))] = &Principal{UserID: user, Role: role}
+	}
+
+	return k, nil
+}
+
+func (k *APIKeys) Len() int {
+	return len(k.keys)
+}
+
+func (k *APIKeys) Lookup(key string) (*Principal, error) {
+	// Keys are compared by digest so lookup time does not depend on how
+	// much of a guessed key matches.
+	sum := sha256.Sum256([]byte(key))
+	for stored, p := range k.keys {
+		if subtle.ConstantTimeCompare(stored[:], sum[:]) == 1 {
+			return p, nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"testTask/internal/handlers"
+	"testTask/pkg/logging"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func init() {
+	handlers.RegisterErrors(ErrorMappings()...)
+}
+
+func TestJWTVerifier_RoundTrip(t *testing.T) {
+	v := NewJWTVerifier(testSecret, "qa")
+
+	token, err := v.Sign(&Principal{UserID: "u1", Role: RoleModerator}, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+
+	p, err := v.Verify(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "u1", p.UserID)
+	assert.Equal(t, RoleModerator, p.Role)
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	v := NewJWTVerifier(testSecret, "qa")
+	other := NewJWTVerifier("ffffffffffffffffffffffffffffffff", "qa")
+	future := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	expired, _ := v.Sign(&Principal{UserID: "u1"}, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	})
+	noExpiry, _ := v.Sign(&Principal{UserID: "u1"}, jwt.RegisteredClaims{})
+	wrongKey, _ := other.Sign(&Principal{UserID: "u1"}, jwt.RegisteredClaims{ExpiresAt: future})
+	wrongIssuer, _ := v.Sign(&Principal{UserID: "u1"}, jwt.RegisteredClaims{ExpiresAt: future, Issuer: "other"})
+	noSubject, _ := v.Sign(&Principal{}, jwt.RegisteredClaims{ExpiresAt: future})
+
+	for name, token := range map[string]string{
+		"expired":      expired,
+		"no expiry":    noExpiry,
+		"wrong key":    wrongKey,
+		"wrong issuer": wrongIssuer,
+		"no subject":   noSubject,
+		"garbage":      "not.a.jwt",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(token)
+			assert.True(t, errors.Is(err, ErrInvalidCredentials))
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("k1=alice, k2=bob:moderator")
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+
+	p, err := keys.Lookup("k2")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", p.UserID)
+	assert.Equal(t, RoleModerator, p.Role)
+
+	p, err = keys.Lookup("k1")
+	require.NoError(t, err)
+	assert.Equal(t, RoleUser, p.Role)
+
+	_, err = keys.Lookup("k3")
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+
+	_, err = ParseAPIKeys("k1")
+	assert.Error(t, err)
+
+	_, err = ParseAPIKeys("k1=alice:owner")
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	keys, err := ParseAPIKeys("k1=alice")
+	require.NoError(t, err)
+
+	var seen *Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := Middleware(NewAuthenticator(nil, keys), logging.GetLogger())(next)
+
+	t.Run("anonymous", func(t *testing.T) {
+		seen = nil
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/questions/", nil))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Nil(t, seen)
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		seen = nil
+		req := httptest.NewRequest(http.MethodPost, "/questions/", nil)
+		req.Header.Set(APIKeyHeader, "k1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		require.NotNil(t, seen)
+		assert.Equal(t, "alice", seen.UserID)
+	})
+
+	t.Run("bad credentials", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/questions/", nil)
+		req.Header.Set("Authorization", "Bearer whatever")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	})
+}
+
+func TestRolePolicy(t *testing.T) {
+	policy := NewPolicy()
+	author := &Principal{UserID: "alice", Role: RoleUser}
+	other := &Principal{UserID: "bob", Role: RoleUser}
+	moderator := &Principal{UserID: "mod", Role: RoleModerator}
+	admin := &Principal{UserID: "root", Role: RoleAdmin}
+
+	assert.NoError(t, policy.Authorize(author, ActionEdit, "alice"))
+	assert.NoError(t, policy.Authorize(author, ActionDelete, "alice"))
+	assert.NoError(t, policy.Authorize(moderator, ActionDelete, "alice"))
+	assert.NoError(t, policy.Authorize(moderator, ActionViewTrash, ""))
+	assert.NoError(t, policy.Authorize(admin, ActionRestore, ""))
+	assert.NoError(t, policy.Authorize(moderator, ActionRestore, "alice"))
+	assert.NoError(t, policy.Authorize(admin, ActionManageTag, ""))
+	assert.NoError(t, policy.Authorize(author, ActionAccept, "alice"))
+	assert.NoError(t, policy.Authorize(moderator, ActionLock, "alice"))
+
+	assert.True(t, errors.Is(policy.Authorize(other, ActionDelete, "alice"), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(other, ActionEdit, ""), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(author, ActionViewTrash, ""), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(admin, ActionAccept, "alice"), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(author, ActionLock, "alice"), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(author, ActionRestore, "alice"), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(moderator, ActionManageTag, ""), ErrForbidden))
+	assert.True(t, errors.Is(policy.Authorize(nil, ActionEdit, "alice"), ErrUnauthenticated))
+}
+
+func TestForbiddenProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/answers/1", nil)
+	handlers.Error(rec, req, &ForbiddenError{Action: ActionDelete, Reason: "nope"})
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, handlers.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Forbidden",
+		"status": 403,
+		"detail": "forbidden: delete: nope",
+		"instance": "/answers/1",
+		"code": "forbidden",
+		"action": "delete",
+		"reason": "nope"
+	}`, rec.Body.String())
+}
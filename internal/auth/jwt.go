@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTVerifier accepts HMAC-SHA256 signed bearer tokens. The subject claim
+// becomes the principal's user id and the role claim its role.
+type JWTVerifier struct {
+	secret []byte
+	issuer string
+}
+
+func NewJWTVerifier(secret, issuer string) *JWTVerifier {
+	return &JWTVerifier{secret: []byte(secret), issuer: issuer}
+}
+
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return v.secret, nil
+	}, opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	sub := strings.TrimSpace(claims.Subject)
+	if sub == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidCredentials)
+	}
+
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	return &Principal{UserID: sub, Role: role}, nil
+}
+
+// Sign issues a token for p. It is used by tests and local tooling; the
+// service itself never hands out tokens.
+func (v *JWTVerifier) Sign(p *Principal, claims jwt.RegisteredClaims) (string, error) {
+	claims.Subject = p.UserID
+	if claims.Issuer == "" {
+		claims.Issuer = v.issuer
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		RegisteredClaims: claims,
+		Role:             string(p.Role),
+	}).SignedString(v.secret)
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"testTask/internal/handlers"
+	"testTask/pkg/logging"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// ErrorMappings reports authentication failures as 401 and refused
+// actions as 403. main registers them with handlers.RegisterErrors.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrUnauthenticated, Status: http.StatusUnauthorized, Code: "unauthenticated"},
+		{Err: ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "invalid_credentials"},
+		{Err: ErrForbidden, Status: http.StatusForbidden, Code: "forbidden"},
+	}
+}
+
+// Authenticator resolves the credentials of a request. Either source may
+// be nil when it is not configured.
+type Authenticator struct {
+	jwt  *JWTVerifier
+	keys *APIKeys
+}
+
+func NewAuthenticator(jwt *JWTVerifier, keys *APIKeys) *Authenticator {
+	return &Authenticator{jwt: jwt, keys: keys}
+}
+
+// Authenticate returns (nil, nil) for requests without credentials.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if a.keys == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return a.keys.Lookup(key)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || a.jwt == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return a.jwt.Verify(strings.TrimSpace(token))
+}
+
+// Middleware puts the authenticated principal into the request context.
+// Anonymous requests pass through; it is up to the services to demand a
+// principal. Requests with bad credentials are rejected outright.
+func Middleware(authn *Authenticator, logger *logging.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := authn.Authenticate(r)
+			if err != nil {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					logger.Ctx(r.Context()).Errorf("authenticate request: %v", err)
+				}
+				handlers.Error(w, r, ErrInvalidCredentials)
+				return
+			}
+
+			if p != nil {
+				ctx := WithPrincipal(r.Context(), p)
+				ctx = logging.NewContext(ctx, logger.Ctx(ctx).With(logging.Fields{"user_id": p.UserID}))
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case "":
+		return RoleUser, nil
+	case RoleUser, RoleModerator, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// rank orders roles so that higher roles inherit what lower ones may do.
+func (r Role) rank() int {
+	switch r {
+	case RoleAdmin:
+		return 2
+	case RoleModerator:
+		return 1
+	}
+	return 0
+}
+
+func (r Role) AtLeast(other Role) bool {
+	return r.rank() >= other.rank()
+}
+
+type Action string
+
+const (
+	ActionEdit      Action = "edit"
+	ActionDelete    Action = "delete"
+	ActionRestore   Action = "restore"
+	ActionViewTrash Action = "view_trash"
+	ActionManageTag Action = "manage_tag"
+	ActionAccept    Action = "accept"
+	ActionLock      Action = "lock"
+	// ActionReopenClosed is reopening a question a moderator closed.
+	ActionReopenClosed Action = "reopen_closed"
+)
+
+// ForbiddenError explains which action was refused. It matches ErrForbidden
+// with errors.Is and is written to clients as is.
+type ForbiddenError struct {
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %s: %s", e.Action, e.Reason)
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// ProblemExtensions adds the refused action and the reason to the
+// problem response.
+func (e *ForbiddenError) ProblemExtensions() map[string]any {
+	return map[string]any{"action": e.Action, "reason": e.Reason}
+}
+
+// Policy decides whether p may perform action on a resource owned by
+// ownerID. An empty ownerID means the resource has no owner.
+type Policy interface {
+	Authorize(p *Principal, action Action, ownerID string) error
+}
+
+// RolePolicy lets authors edit and delete their own content and moderators
+// and admins act on anything. Trash listings, restoring and locking are for
+// moderators, renaming and merging tags is for admins only and accepting an
+// answer is for the question author only. Restoring is not left to authors
+// so that they cannot bring back what a moderator removed.
+type RolePolicy struct{}
+
+func NewPolicy() Policy {
+	return RolePolicy{}
+}
+
+func (RolePolicy) Authorize(p *Principal, action Action, ownerID string) error {
+	if p == nil {
+		return ErrUnauthenticated
+	}
+	if action == ActionAccept {
+		if ownerID != "" && ownerID == p.UserID {
+			return nil
+		}
+		return &ForbiddenError{Action: action, Reason: "only the question author may accept an answer"}
+	}
+	if action == ActionManageTag {
+		if p.Role.AtLeast(RoleAdmin) {
+			return nil
+		}
+		return &ForbiddenError{Action: action, Reason: "admin role required"}
+	}
+	if p.Role.AtLeast(RoleModerator) {
+		return nil
+	}
+
+	switch action {
+	case ActionEdit, ActionDelete:
+		if ownerID != "" && ownerID == p.UserID {
+			return nil
+		}
+		return &ForbiddenError{Action: action, Reason: "only the author or a moderator may do this"}
+	}
+
+	return &ForbiddenError{Action: action, Reason: "moderator role required"}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUnauthenticated    = errors.New("authentication required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID string `json:"user_id"`
+	Role   Role   `json:"role"`
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Require returns the principal of ctx or ErrUnauthenticated for
+// anonymous requests.
+func Require(ctx context.Context) (*Principal, error) {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return p, nil
+}
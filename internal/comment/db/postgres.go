@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testTask/internal/comment"
+	"testTask/pkg/logging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// targets maps what a comment is attached to onto the parent table and
+// the comment column pointing at it.
+var targets = map[comment.Target]struct{ table, column string }{
+	comment.TargetQuestion: {table: "questions", column: "question_id"},
+	comment.TargetAnswer:   {table: "answers", column: "answer_id"},
+}
+
+type repository struct {
+	db     *gorm.DB
+	logger *logging.Logger
+}
+
+func NewStorage(db *gorm.DB, logger *logging.Logger) comment.Storage {
+	return &repository{db: db, logger: logger}
+}
+
+func (r *repository) Create(ctx context.Context, c *comment.Comment) (*comment.Comment, error) {
+	target, targetID := c.Target()
+	t, ok := targets[target]
+	if !ok {
+		return nil, comment.ErrTargetNotFound
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A shared lock keeps the parent from being soft-deleted until the
+		// comment is in, so the delete cascades to it as well.
+		if err := findParent(tx.Clauses(clause.Locking{Strength: "SHARE"}), t.table, targetID); err != nil {
+			return err
+		}
+		return tx.Create(c).Error
+	})
+	if err != nil {
+		if errors.Is(err, comment.ErrTargetNotFound) {
+			return nil, err
+		}
+		r.logger.Ctx(ctx).Errorf("failed to create comment: %v", err)
+		return nil, fmt.Errorf("create comment: %w", err)
+	}
+	return c, nil
+}
+
+// findParent returns comment.ErrTargetNotFound unless id is a live row of
+// table.
+func findParent(db *gorm.DB, table string, id uint) error {
+	var parent struct{ ID uint }
+	err := db.Table(table).
+		Select("id").
+		Where("id = ? AND deleted_at IS NULL", id).
+		Take(&parent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return comment.ErrTargetNotFound
+	}
+	return err
+}
+
+func (r *repository) FindOne(ctx context.Context, id uint) (*comment.Comment, error) {
+	var c comment.Comment
+	if err := r.db.WithContext(ctx).First(&c, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find comment id=%d: %v", id, err)
+		return nil, fmt.Errorf("find comment: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *repository) FindByTarget(ctx context.Context, target comment.Target, targetID uint, filter comment.ListFilter) ([]comment.Comment, error) {
+	t, ok := targets[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown comment target %q", target)
+	}
+
+	if err := findParent(r.db.WithContext(ctx), t.table, targetID); err != nil {
+		if errors.Is(err, comment.ErrTargetNotFound) {
+			return nil, err
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find %s id=%d: %v", target, targetID, err)
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+
+	var list []comment.Comment
+
+	query := r.db.WithContext(ctx).Where(t.column+" = ?", targetID)
+	if after := filter.After; after != nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", after.Time, after.Time, after.ID)
+	}
+
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Limit(filter.Limit).
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list comments on %s id=%d: %v", target, targetID, err)
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) Update(ctx context.Context, c *comment.Comment) (*comment.Comment, error) {
+	if err := r.db.WithContext(ctx).Model(c).Update("text", c.Text).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to update comment id=%d: %v", c.ID, err)
+		return nil, fmt.Errorf("update comment: %w", err)
+	}
+	return c, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&comment.Comment{}, id).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to delete comment id=%d: %v", id, err)
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&comment.Comment{})
+	if res.Error != nil {
+		r.logger.Ctx(ctx).Errorf("failed to purge deleted comments: %v", res.Error)
+		return 0, fmt.Errorf("purge comments: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,155 @@
+package comment
+
+import (
+	"net/http"
+	"strconv"
+	"testTask/internal/handlers"
+	"testTask/internal/pagination"
+	"testTask/pkg/logging"
+)
+
+// ErrorMappings tells handlers.Error how to report comment errors.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrNotFound, Status: http.StatusNotFound, Code: "comment_not_found"},
+		{Err: ErrTargetNotFound, Status: http.StatusNotFound, Code: "comment_target_not_found"},
+	}
+}
+
+type handler struct {
+	logger  *logging.Logger
+	service Service
+}
+
+func NewHandler(logger *logging.Logger, service Service) handlers.Handler {
+	return &handler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *handler) Register(router *http.ServeMux) {
+	router.HandleFunc("GET /questions/{id}/comments/", h.list(TargetQuestion))
+	router.HandleFunc("POST /questions/{id}/comments/", h.create(TargetQuestion))
+	router.HandleFunc("GET /answers/{id}/comments/", h.list(TargetAnswer))
+	router.HandleFunc("POST /answers/{id}/comments/", h.create(TargetAnswer))
+	router.HandleFunc("GET /comments/{id}", h.GetById)
+	router.HandleFunc("PUT /comments/{id}", h.Update)
+	router.HandleFunc("PATCH /comments/{id}", h.Update)
+	router.HandleFunc("DELETE /comments/{id}", h.Delete)
+}
+
+func (h *handler) list(target Target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := handlers.PathID(r, "id")
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		params := ListParams{Cursor: r.URL.Query().Get("cursor")}
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				handlers.Error(w, r, pagination.ErrInvalidLimit)
+				return
+			}
+			params.Limit = n
+		}
+
+		page, err := h.service.List(r.Context(), target, id, params)
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		handlers.WriteJSON(w, http.StatusOK, page)
+	}
+}
+
+func (h *handler) create(target Target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := handlers.PathID(r, "id")
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		var req CreateCommentRequest
+		if err := handlers.ReadJSON(r, &req); err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+			}
+		}()
+
+		c, err := h.service.Create(r.Context(), target, id, &req)
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		handlers.WriteJSON(w, http.StatusCreated, c)
+	}
+}
+
+func (h *handler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	c, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, c)
+}
+
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := handlers.ReadJSON(r, &req); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+		}
+	}()
+
+	c, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, c)
+}
+
+func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package comment
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Target is the kind of post a comment is attached to.
+type Target string
+
+const (
+	TargetQuestion Target = "question"
+	TargetAnswer   Target = "answer"
+)
+
+// Comment is a short remark under a question or an answer. Exactly one of
+// QuestionID and AnswerID is set.
+type Comment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	QuestionID *uint     `gorm:"index" json:"question_id,omitempty"`
+	AnswerID   *uint     `gorm:"index" json:"answer_id,omitempty"`
+	UserID     string    `gorm:"type:varchar(64);not null" json:"user_id"`
+	Text       string    `gorm:"type:text;not null" json:"text"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"`
+}
+
+// Target reports what the comment is attached to.
+func (c *Comment) Target() (Target, uint) {
+	if c.AnswerID != nil {
+		return TargetAnswer, *c.AnswerID
+	}
+	if c.QuestionID != nil {
+		return TargetQuestion, *c.QuestionID
+	}
+	return "", 0
+}
+
+type CreateCommentRequest struct {
+	Text string `json:"text" validate:"required,max=600"`
+}
+
+type UpdateCommentRequest struct {
+	Text string `json:"text" validate:"required,max=600"`
+}
+
+type ListParams struct {
+	Limit  int
+	Cursor string
+}
+
+// ListFilter is the resolved form of ListParams handed to Storage.
+type ListFilter struct {
+	Limit int
+	After *Position
+}
+
+// Position is a decoded keyset cursor over (created_at, id).
+type Position struct {
+	Time time.Time
+	ID   uint
+}
@@ -0,0 +1,213 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"testTask/internal/auth"
+	"testTask/internal/metrics"
+	"testTask/internal/pagination"
+	"testTask/internal/tracing"
+	"testTask/internal/validation"
+	"testTask/pkg/logging"
+)
+
+var (
+	ErrNotFound       = errors.New("comment not found")
+	ErrTargetNotFound = errors.New("commented post not found")
+)
+
+type Service interface {
+	Create(ctx context.Context, target Target, targetID uint, req *CreateCommentRequest) (*Comment, error)
+	GetByID(ctx context.Context, id uint) (*Comment, error)
+	List(ctx context.Context, target Target, targetID uint, params ListParams) (*pagination.Page[Comment], error)
+	Update(ctx context.Context, id uint, req *UpdateCommentRequest) (*Comment, error)
+	Delete(ctx context.Context, id uint) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type service struct {
+	storage Storage
+	policy  auth.Policy
+	logger  *logging.Logger
+}
+
+func NewService(storage Storage, policy auth.Policy, logger *logging.Logger) Service {
+	return &service{
+		storage: storage,
+		policy:  policy,
+		logger:  logger,
+	}
+}
+
+func (s *service) Create(ctx context.Context, target Target, targetID uint, req *CreateCommentRequest) (_ *Comment, err error) {
+	ctx, span := tracing.Start(ctx, "comment.Create")
+	defer tracing.End(span, &err)
+
+	author, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	c := &Comment{
+		UserID: author.UserID,
+		Text:   strings.TrimSpace(req.Text),
+	}
+	switch target {
+	case TargetQuestion:
+		c.QuestionID = &targetID
+	case TargetAnswer:
+		c.AnswerID = &targetID
+	default:
+		return nil, ErrTargetNotFound
+	}
+
+	created, err := s.storage.Create(ctx, c)
+	if err != nil {
+		if !errors.Is(err, ErrTargetNotFound) {
+			s.logger.Ctx(ctx).Errorf("failed to create comment on %s id=%d: %v", target, targetID, err)
+		}
+		return nil, err
+	}
+	metrics.CommentsCreated.Inc()
+
+	return created, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id uint) (_ *Comment, err error) {
+	ctx, span := tracing.Start(ctx, "comment.GetByID")
+	defer tracing.End(span, &err)
+
+	c, err := s.storage.FindOne(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get comment id=%d: %v", id, err)
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *service) List(ctx context.Context, target Target, targetID uint, params ListParams) (_ *pagination.Page[Comment], err error) {
+	ctx, span := tracing.Start(ctx, "comment.List")
+	defer tracing.End(span, &err)
+
+	limit, err := pagination.Limit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	filter := ListFilter{Limit: limit + 1}
+
+	c, err := pagination.Decode(params.Cursor, cursorKey)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		filter.After = &Position{Time: t, ID: c.ID}
+	}
+
+	list, err := s.storage.FindByTarget(ctx, target, targetID, filter)
+	if err != nil {
+		if !errors.Is(err, ErrTargetNotFound) {
+			s.logger.Ctx(ctx).Errorf("failed to list comments on %s id=%d: %v", target, targetID, err)
+		}
+		return nil, err
+	}
+
+	page := &pagination.Page[Comment]{Items: list}
+	if page.Items == nil {
+		page.Items = []Comment{}
+	}
+	if len(list) > limit {
+		page.Items = list[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = pagination.Cursor{
+			Key:   cursorKey,
+			Value: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			ID:    last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
+
+func (s *service) Update(ctx context.Context, id uint, req *UpdateCommentRequest) (_ *Comment, err error) {
+	ctx, span := tracing.Start(ctx, "comment.Update")
+	defer tracing.End(span, &err)
+
+	editor, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	c, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(editor, auth.ActionEdit, c.UserID); err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if c.Text == text {
+		return c, nil
+	}
+	c.Text = text
+
+	updated, err := s.storage.Update(ctx, c)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to update comment id=%d: %v", id, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "comment.Delete")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionDelete, c.UserID); err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(ctx, id); err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to delete comment id=%d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Purge(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "comment.Purge")
+	defer tracing.End(span, &err)
+
+	n, err := s.storage.Purge(ctx, before)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to purge deleted comments: %v", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+const cursorKey = "created_at:asc"
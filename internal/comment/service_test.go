@@ -0,0 +1,194 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"testTask/internal/auth"
+	"testTask/internal/pagination"
+	"testTask/internal/validation"
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	mock.Mock
+}
+
+func (m *mockStorage) Create(ctx context.Context, c *Comment) (*Comment, error) {
+	args := m.Called(ctx, c)
+	if v := args.Get(0); v != nil {
+		return v.(*Comment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) FindOne(ctx context.Context, id uint) (*Comment, error) {
+	args := m.Called(ctx, id)
+	if v := args.Get(0); v != nil {
+		return v.(*Comment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) FindByTarget(ctx context.Context, target Target, targetID uint, filter ListFilter) ([]Comment, error) {
+	args := m.Called(ctx, target, targetID, filter)
+	if v := args.Get(0); v != nil {
+		return v.([]Comment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) Update(ctx context.Context, c *Comment) (*Comment, error) {
+	args := m.Called(ctx, c)
+	if v := args.Get(0); v != nil {
+		return v.(*Comment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStorage) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockStorage) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newTestService(t *testing.T) (*service, *mockStorage) {
+	t.Helper()
+
+	storage := &mockStorage{}
+	return &service{storage: storage, policy: auth.NewPolicy(), logger: logging.GetLogger()}, storage
+}
+
+func withUser(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleUser})
+}
+
+func TestService_Create_OnAnswer(t *testing.T) {
+	svc, storage := newTestService(t)
+	answerID := uint(7)
+
+	storage.
+		On("Create", mock.Anything, mock.MatchedBy(func(c *Comment) bool {
+			target, id := c.Target()
+			return target == TargetAnswer && id == 7 &&
+				c.QuestionID == nil &&
+				c.UserID == "alice" &&
+				c.Text == "nice"
+		})).
+		Return(&Comment{ID: 1, AnswerID: &answerID, UserID: "alice", Text: "nice"}, nil)
+
+	c, err := svc.Create(withUser("alice"), TargetAnswer, 7, &CreateCommentRequest{Text: " nice "})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), c.ID)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Create_TargetNotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("Create", mock.Anything, mock.Anything).
+		Return(nil, ErrTargetNotFound)
+
+	_, err := svc.Create(withUser("alice"), TargetQuestion, 999, &CreateCommentRequest{Text: "hi"})
+
+	assert.True(t, errors.Is(err, ErrTargetNotFound))
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	_, err := svc.Create(withUser("alice"), TargetQuestion, 1, &CreateCommentRequest{Text: "  "})
+
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, "text", ve.Fields[0].Field)
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	_, err = svc.Create(context.Background(), TargetQuestion, 1, &CreateCommentRequest{Text: "hi"})
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+}
+
+func TestService_List_Pages(t *testing.T) {
+	svc, storage := newTestService(t)
+	base := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	storage.
+		On("FindByTarget", mock.Anything, TargetQuestion, uint(3), ListFilter{Limit: 3}).
+		Return([]Comment{
+			{ID: 1, CreatedAt: base},
+			{ID: 2, CreatedAt: base.Add(time.Second)},
+			{ID: 3, CreatedAt: base.Add(2 * time.Second)},
+		}, nil)
+
+	page, err := svc.List(context.Background(), TargetQuestion, 3, ListParams{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	c, err := pagination.Decode(page.NextCursor, cursorKey)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), c.ID)
+
+	after := base.Add(time.Second)
+	storage.
+		On("FindByTarget", mock.Anything, TargetQuestion, uint(3), ListFilter{Limit: 3, After: &Position{Time: after, ID: 2}}).
+		Return([]Comment{{ID: 3, CreatedAt: base.Add(2 * time.Second)}}, nil)
+
+	page, err = svc.List(context.Background(), TargetQuestion, 3, ListParams{Limit: 2, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestService_List_TargetNotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindByTarget", mock.Anything, TargetAnswer, uint(999), ListFilter{Limit: pagination.DefaultLimit + 1}).
+		Return(nil, ErrTargetNotFound)
+
+	page, err := svc.List(context.Background(), TargetAnswer, 999, ListParams{})
+
+	assert.True(t, errors.Is(err, ErrTargetNotFound))
+	assert.Nil(t, page)
+}
+
+func TestService_Update_Forbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(4)).
+		Return(&Comment{ID: 4, UserID: "alice", Text: "old"}, nil)
+
+	_, err := svc.Update(withUser("bob"), 4, &UpdateCommentRequest{Text: "new"})
+
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_NotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(4)).
+		Return(nil, nil)
+
+	err := svc.Delete(withUser("alice"), 4)
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
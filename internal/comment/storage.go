@@ -0,0 +1,21 @@
+package comment
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	// Create returns ErrTargetNotFound unless the question or answer the
+	// comment is attached to is live.
+	Create(ctx context.Context, c *Comment) (*Comment, error)
+	FindOne(ctx context.Context, id uint) (*Comment, error)
+	// FindByTarget returns ErrTargetNotFound unless the question or answer
+	// is live.
+	FindByTarget(ctx context.Context, target Target, targetID uint, filter ListFilter) ([]Comment, error)
+	Update(ctx context.Context, c *Comment) (*Comment, error)
+	Delete(ctx context.Context, id uint) error
+	// Purge hard-deletes comments that were soft-deleted before the given
+	// time and returns how many were removed.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
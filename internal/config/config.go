@@ -1,55 +1,246 @@
+// Package config builds the service configuration from defaults, an
+// optional YAML or TOML file, environment variables and command-line
+// flags, each layer overriding the one before.
 package config
 
 import (
 	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
+
+	"testTask/internal/auth"
+	"testTask/internal/tracing"
+	"testTask/pkg/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Storage backends selectable with STORAGE.
+const (
+	StoragePostgres = "postgres"
+	// StorageSQLite keeps everything in a single database file, for
+	// local runs and small deployments.
+	StorageSQLite = "sqlite"
+	// StorageMemory keeps everything in process memory, for demos and
+	// end-to-end tests without a database.
+	StorageMemory = "memory"
 )
 
 type Config struct {
-	DBHost     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBPort     string
-	DBSSLMode  string
+	Storage string
+
+	HTTP   HTTPConfig
+	DB     DBConfig
+	SQLite SQLiteConfig
+	Auth   AuthConfig
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	ArchiveAfter    time.Duration
+	ArchiveInterval time.Duration
+
+	Features Features
+
+	Log     logging.Config
+	Tracing tracing.Config
+
+	// sources records where Load took each setting from, by key.
+	sources map[string]string
+}
+
+type HTTPConfig struct {
+	Addr string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ReadinessTimeout bounds the dependency checks of /readyz,
+	// ShutdownDrain is how long /readyz fails before the server stops and
+	// ShutdownTimeout how long in-flight requests get after that.
+	ReadinessTimeout time.Duration
+	ShutdownDrain    time.Duration
+	ShutdownTimeout  time.Duration
+}
+
+type DBConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	// Pool settings apply to Postgres and SQLite alike; zero means no
+	// limit.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+type SQLiteConfig struct {
+	Path string
+}
+
+type AuthConfig struct {
+	JWTSecret string
+	JWTIssuer string
+	APIKeys   string
+}
+
+// Features switch optional parts of the service on and off.
+type Features struct {
+	Comments bool
+	Votes    bool
+	Search   bool
+	// Archive runs the job archiving inactive questions.
+	Archive bool
+}
 
-	DSN string
+// Default returns the configuration used where no layer sets a value.
+func Default() *Config {
+	return &Config{
+		Storage: StoragePostgres,
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ReadinessTimeout:  2 * time.Second,
+			ShutdownDrain:     5 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
+		},
+		DB: DBConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		SQLite: SQLiteConfig{Path: "./data/testtask.db"},
+
+		TrashRetention:     30 * 24 * time.Hour,
+		TrashPurgeInterval: time.Hour,
+		ArchiveAfter:       180 * 24 * time.Hour,
+		ArchiveInterval:    time.Hour,
+
+		Features: Features{Comments: true, Votes: true, Search: true, Archive: true},
+
+		Log: logging.Config{
+			Format:     logging.FormatJSON,
+			Level:      "info",
+			Outputs:    []string{logging.OutputStdout},
+			File:       "./logs/app.log",
+			MaxSizeMB:  100,
+			MaxAge:     7 * 24 * time.Hour,
+			MaxBackups: 5,
+		},
+		Tracing: tracing.Config{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "testTask",
+			SampleRatio: 1,
+		},
+	}
 }
 
-func LoadConfig() (*Config, error) {
-	cfg := &Config{
-		DBHost:     os.Getenv("DB_HOST"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:     os.Getenv("DB_NAME"),
-		DBPort:     os.Getenv("DB_PORT"),
-		DBSSLMode:  os.Getenv("DB_SSLMODE"),
+// DSN is the Postgres connection string in key=value form, with values
+// quoted so that passwords may contain spaces and quotes.
+func (c DBConfig) DSN() string {
+	quote := func(v string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
 	}
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		quote(c.Host), quote(c.User), quote(c.Password), quote(c.Name), c.Port, quote(c.SSLMode))
+}
 
-	if cfg.DBPort == "" {
-		cfg.DBPort = "5432"
+// Validate reports every problem with the configuration at once, one per
+// line, named by the setting keys.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
 	}
-	if cfg.DBSSLMode == "" {
-		cfg.DBSSLMode = "disable"
+	positive := func(key string, d time.Duration) {
+		check(d > 0, key, "must be positive, got %s", d)
 	}
 
-	if cfg.DBHost == "" ||
-		cfg.DBUser == "" ||
-		cfg.DBPassword == "" ||
-		cfg.DBName == "" {
-		return nil, errors.New("missing required DB env vars")
+	switch c.Storage {
+	case StoragePostgres:
+		check(c.DB.Host != "", "db.host", "is required for postgres storage")
+		check(c.DB.User != "", "db.user", "is required for postgres storage")
+		check(c.DB.Password != "", "db.password", "is required for postgres storage")
+		check(c.DB.Name != "", "db.name", "is required for postgres storage")
+		check(c.DB.Port > 0 && c.DB.Port < 65536, "db.port", "must be between 1 and 65535, got %d", c.DB.Port)
+	case StorageSQLite:
+		check(c.SQLite.Path != "", "sqlite.path", "is required for sqlite storage")
+	case StorageMemory:
+	default:
+		check(false, "storage", "must be one of %s, %s, %s, got %q", StoragePostgres, StorageSQLite, StorageMemory, c.Storage)
 	}
 
-	cfg.DSN = fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		cfg.DBHost,
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBName,
-		cfg.DBPort,
-		cfg.DBSSLMode,
-	)
+	check(c.HTTP.Addr != "", "http.addr", "is required")
+	positive("http.read_timeout", c.HTTP.ReadTimeout)
+	positive("http.read_header_timeout", c.HTTP.ReadHeaderTimeout)
+	positive("http.write_timeout", c.HTTP.WriteTimeout)
+	positive("http.idle_timeout", c.HTTP.IdleTimeout)
+	positive("http.readiness_timeout", c.HTTP.ReadinessTimeout)
+	positive("http.shutdown_drain", c.HTTP.ShutdownDrain)
+	positive("http.shutdown_timeout", c.HTTP.ShutdownTimeout)
+
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns", "must not be negative, got %d", c.DB.MaxOpenConns)
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns", "must not be negative, got %d", c.DB.MaxIdleConns)
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns",
+		"must not exceed db.max_open_conns (%d), got %d", c.DB.MaxOpenConns, c.DB.MaxIdleConns)
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime", "must not be negative, got %s", c.DB.ConnMaxLifetime)
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time", "must not be negative, got %s", c.DB.ConnMaxIdleTime)
+
+	check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= 32, "auth.jwt_secret", "must be at least 32 bytes")
+	if c.Auth.APIKeys != "" {
+		if _, err := auth.ParseAPIKeys(c.Auth.APIKeys); err != nil {
+			check(false, "auth.api_keys", "%v", err)
+		}
+	}
+
+	positive("trash.retention", c.TrashRetention)
+	positive("trash.purge_interval", c.TrashPurgeInterval)
+	positive("archive.after", c.ArchiveAfter)
+	positive("archive.interval", c.ArchiveInterval)
+
+	check(c.Log.Format == logging.FormatJSON || c.Log.Format == logging.FormatText, "log.format",
+		"must be %s or %s, got %q", logging.FormatJSON, logging.FormatText, c.Log.Format)
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		check(false, "log.level", "unknown level %q", c.Log.Level)
+	}
+	check(len(c.Log.Outputs) > 0, "log.outputs", "must name at least one output")
+	for _, out := range c.Log.Outputs {
+		switch out {
+		case logging.OutputStdout, logging.OutputStderr:
+		case logging.OutputFile:
+			check(c.Log.File != "", "log.file", "is required with the file output")
+		default:
+			check(false, "log.outputs", "unknown output %q", out)
+		}
+	}
+	check(c.Log.MaxSizeMB >= 0, "log.max_size_mb", "must not be negative, got %d", c.Log.MaxSizeMB)
+	check(c.Log.MaxAge >= 0, "log.max_age", "must not be negative, got %s", c.Log.MaxAge)
+	check(c.Log.MaxBackups >= 0, "log.max_backups", "must not be negative, got %d", c.Log.MaxBackups)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		check(false, "tracing.exporter", "must be one of %s, %s, %s, got %q",
+			tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio",
+		"must be between 0 and 1, got %g", c.Tracing.SampleRatio)
 
-	return cfg, nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return Load(flags, args)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+storage: sqlite
+http:
+  addr: ":7000"
+  read_timeout: 20s
+  write_timeout: 40s
+db:
+  max_open_conns: 10
+log:
+  outputs: [stdout, stderr]
+`)
+	t.Setenv("TESTTASK_HTTP_ADDR", ":7001")
+	t.Setenv("TESTTASK_HTTP_READ_TIMEOUT", "21s")
+	t.Setenv("DB_MAX_OPEN_CONNS", "99") // not a legacy name, ignored
+
+	cfg, err := load(t, "--config", path, "--http-addr", ":7002")
+	require.NoError(t, err)
+
+	assert.Equal(t, StorageSQLite, cfg.Storage)
+	assert.Equal(t, ":7002", cfg.HTTP.Addr)
+	assert.Equal(t, 21*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, 40*time.Second, cfg.HTTP.WriteTimeout)
+	assert.Equal(t, 5*time.Second, cfg.HTTP.ReadHeaderTimeout)
+	assert.Equal(t, 10, cfg.DB.MaxOpenConns)
+	assert.Equal(t, []string{"stdout", "stderr"}, cfg.Log.Outputs)
+
+	assert.Equal(t, "flag --http-addr", cfg.sources["http.addr"])
+	assert.Equal(t, "env TESTTASK_HTTP_READ_TIMEOUT", cfg.sources["http.read_timeout"])
+	assert.Equal(t, "file "+path, cfg.sources["http.write_timeout"])
+}
+
+func TestLoad_LegacyEnv(t *testing.T) {
+	t.Setenv("STORAGE", "sqlite")
+	t.Setenv("APP_PORT", ":9000")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("TESTTASK_LOG_LEVEL", "warn")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, StorageSQLite, cfg.Storage)
+	assert.Equal(t, ":9000", cfg.HTTP.Addr)
+	assert.Equal(t, "warn", cfg.Log.Level, "the prefixed name wins")
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+storage = "memory"
+
+[http]
+shutdown_timeout = "10s"
+
+[features]
+votes = false
+
+[tracing]
+sample_ratio = 0.25
+`)
+	t.Setenv(ConfigFileEnv, path)
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, StorageMemory, cfg.Storage)
+	assert.Equal(t, 10*time.Second, cfg.HTTP.ShutdownTimeout)
+	assert.False(t, cfg.Features.Votes)
+	assert.True(t, cfg.Features.Comments)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+}
+
+func TestLoad_SecretFile(t *testing.T) {
+	secret := writeFile(t, "password", "s3cret\n")
+	t.Setenv("STORAGE", "postgres")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "testTask")
+	t.Setenv("DB_PASSWORD_FILE", secret)
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.DB.Password)
+
+	t.Setenv("DB_PASSWORD", "other")
+	_, err = load(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both DB_PASSWORD and DB_PASSWORD_FILE are set")
+}
+
+func TestLoad_NoFlagsForSecrets(t *testing.T) {
+	_, err := load(t, "--db-password", "x")
+	assert.Error(t, err)
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+storage: sqlite
+http:
+  adr: ":1"
+`)
+	t.Setenv("TESTTASK_HTTP_READ_TIMEOUT", "soon")
+
+	_, err := load(t, "--config", path, "--db-port", "x")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http.adr: unknown setting")
+	assert.Contains(t, err.Error(), `http.read_timeout: invalid value "soon" from env TESTTASK_HTTP_READ_TIMEOUT`)
+	assert.Contains(t, err.Error(), `db.port: invalid value "x" from flag --db-port`)
+}
+
+func TestLoad_UnsupportedFile(t *testing.T) {
+	_, err := load(t, "--config", writeFile(t, "config.json", "{}"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Storage = StoragePostgres
+	cfg.HTTP.ReadTimeout = 0
+	cfg.DB.MaxOpenConns = 2
+	cfg.DB.MaxIdleConns = 3
+	cfg.Auth.JWTSecret = "short"
+	cfg.Log.Level = "loud"
+	cfg.Tracing.SampleRatio = 2
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"db.host: is required for postgres storage",
+		"db.password: is required for postgres storage",
+		"http.read_timeout: must be positive",
+		"db.max_idle_conns: must not exceed db.max_open_conns (2), got 3",
+		"auth.jwt_secret: must be at least 32 bytes",
+		`log.level: unknown level "loud"`,
+		"tracing.sample_ratio: must be between 0 and 1",
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+
+	cfg = Default()
+	cfg.Storage = StorageMemory
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	t.Setenv("STORAGE", "postgres")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "testTask")
+	t.Setenv("DB_PASSWORD", "s3cret")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), "s3cret")
+	assert.Regexp(t, `db\.password\s+\*{6}\s+# env DB_PASSWORD`, out.String())
+	assert.Regexp(t, `db\.host\s+db\s+# env DB_HOST`, out.String())
+	assert.Regexp(t, `auth\.jwt_secret\s+# default`, out.String())
+	assert.Regexp(t, `http\.addr\s+:8080\s+# default`, out.String())
+}
+
+func TestLoad_EmptyEnvIsUnset(t *testing.T) {
+	t.Setenv("TESTTASK_STORAGE", "memory")
+	t.Setenv("TESTTASK_LOG_LEVEL", "")
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("TRACING_EXPORTER", "")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "error", cfg.Log.Level)
+	assert.Equal(t, "none", cfg.Tracing.Exporter)
+}
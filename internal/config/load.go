@@ -0,0 +1,272 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load.
+const EnvPrefix = "TESTTASK_"
+
+// ConfigFileEnv names the config file when --config is not given.
+const ConfigFileEnv = EnvPrefix + "CONFIG"
+
+// setting is one configurable value. Its key names it in the config file
+// and, transformed, in the environment and on the command line.
+type setting struct {
+	key   string
+	usage string
+	value flag.Value
+	// secret settings are redacted when printed, can be read from a file
+	// named by the *_FILE variable and have no flag, since command lines
+	// are visible to other users of the host.
+	secret bool
+	// aliases are the unprefixed variable names used before EnvPrefix.
+	aliases []string
+}
+
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+func (s setting) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "storage", usage: "storage backend: postgres, sqlite or memory", value: stringValue{&c.Storage}, aliases: []string{"STORAGE"}},
+
+		{key: "http.addr", usage: "address the HTTP server listens on", value: stringValue{&c.HTTP.Addr}, aliases: []string{"APP_PORT"}},
+		{key: "http.read_timeout", usage: "time to read a whole request", value: durationValue{&c.HTTP.ReadTimeout}},
+		{key: "http.read_header_timeout", usage: "time to read request headers", value: durationValue{&c.HTTP.ReadHeaderTimeout}},
+		{key: "http.write_timeout", usage: "time to write a response", value: durationValue{&c.HTTP.WriteTimeout}},
+		{key: "http.idle_timeout", usage: "time keep-alive connections stay idle", value: durationValue{&c.HTTP.IdleTimeout}},
+		{key: "http.readiness_timeout", usage: "timeout of the /readyz dependency checks", value: durationValue{&c.HTTP.ReadinessTimeout}, aliases: []string{"READINESS_TIMEOUT"}},
+		{key: "http.shutdown_drain", usage: "how long /readyz fails before shutdown", value: durationValue{&c.HTTP.ShutdownDrain}, aliases: []string{"SHUTDOWN_DRAIN"}},
+		{key: "http.shutdown_timeout", usage: "time in-flight requests get on shutdown", value: durationValue{&c.HTTP.ShutdownTimeout}},
+
+		{key: "db.host", usage: "Postgres host", value: stringValue{&c.DB.Host}, aliases: []string{"DB_HOST"}},
+		{key: "db.port", usage: "Postgres port", value: intValue{&c.DB.Port}, aliases: []string{"DB_PORT"}},
+		{key: "db.user", usage: "Postgres user", value: stringValue{&c.DB.User}, aliases: []string{"DB_USER"}},
+		{key: "db.password", usage: "Postgres password", value: stringValue{&c.DB.Password}, secret: true, aliases: []string{"DB_PASSWORD"}},
+		{key: "db.name", usage: "Postgres database", value: stringValue{&c.DB.Name}, aliases: []string{"DB_NAME"}},
+		{key: "db.sslmode", usage: "Postgres sslmode", value: stringValue{&c.DB.SSLMode}, aliases: []string{"DB_SSLMODE"}},
+		{key: "db.max_open_conns", usage: "maximum open connections, 0 for no limit", value: intValue{&c.DB.MaxOpenConns}},
+		{key: "db.max_idle_conns", usage: "maximum idle connections", value: intValue{&c.DB.MaxIdleConns}},
+		{key: "db.conn_max_lifetime", usage: "maximum age of a connection, 0 for no limit", value: durationValue{&c.DB.ConnMaxLifetime}},
+		{key: "db.conn_max_idle_time", usage: "maximum idle time of a connection, 0 for no limit", value: durationValue{&c.DB.ConnMaxIdleTime}},
+
+		{key: "sqlite.path", usage: "SQLite database file", value: stringValue{&c.SQLite.Path}, aliases: []string{"SQLITE_PATH"}},
+
+		{key: "auth.jwt_secret", usage: "HS256 secret of bearer tokens, at least 32 bytes", value: stringValue{&c.Auth.JWTSecret}, secret: true, aliases: []string{"AUTH_JWT_SECRET"}},
+		{key: "auth.jwt_issuer", usage: "required iss claim of bearer tokens", value: stringValue{&c.Auth.JWTIssuer}, aliases: []string{"AUTH_JWT_ISSUER"}},
+		{key: "auth.api_keys", usage: "static API keys as key=user[:role],...", value: stringValue{&c.Auth.APIKeys}, secret: true, aliases: []string{"AUTH_API_KEYS"}},
+
+		{key: "trash.retention", usage: "how long deleted content stays restorable", value: durationValue{&c.TrashRetention}, aliases: []string{"TRASH_RETENTION"}},
+		{key: "trash.purge_interval", usage: "how often the trash is purged", value: durationValue{&c.TrashPurgeInterval}, aliases: []string{"TRASH_PURGE_INTERVAL"}},
+		{key: "archive.after", usage: "inactivity after which questions are archived", value: durationValue{&c.ArchiveAfter}, aliases: []string{"QUESTION_ARCHIVE_AFTER"}},
+		{key: "archive.interval", usage: "how often inactive questions are archived", value: durationValue{&c.ArchiveInterval}, aliases: []string{"QUESTION_ARCHIVE_INTERVAL"}},
+
+		{key: "features.comments", usage: "serve comments", value: boolValue{&c.Features.Comments}},
+		{key: "features.votes", usage: "serve votes", value: boolValue{&c.Features.Votes}},
+		{key: "features.search", usage: "serve full-text search", value: boolValue{&c.Features.Search}},
+		{key: "features.archive", usage: "archive inactive questions", value: boolValue{&c.Features.Archive}},
+
+		{key: "log.format", usage: "log format: json or text", value: stringValue{&c.Log.Format}, aliases: []string{"LOG_FORMAT"}},
+		{key: "log.level", usage: "minimum log level", value: stringValue{&c.Log.Level}, aliases: []string{"LOG_LEVEL"}},
+		{key: "log.outputs", usage: "comma-separated outputs: stdout, stderr, file", value: listValue{&c.Log.Outputs}, aliases: []string{"LOG_OUTPUTS"}},
+		{key: "log.file", usage: "log file of the file output", value: stringValue{&c.Log.File}, aliases: []string{"LOG_FILE"}},
+		{key: "log.max_size_mb", usage: "size at which the log file is rotated", value: intValue{&c.Log.MaxSizeMB}, aliases: []string{"LOG_MAX_SIZE_MB"}},
+		{key: "log.max_age", usage: "age at which rotated log files are removed", value: durationValue{&c.Log.MaxAge}, aliases: []string{"LOG_MAX_AGE"}},
+		{key: "log.max_backups", usage: "number of rotated log files kept", value: intValue{&c.Log.MaxBackups}, aliases: []string{"LOG_MAX_BACKUPS"}},
+
+		{key: "tracing.exporter", usage: "trace exporter: none, stdout or otlp", value: stringValue{&c.Tracing.Exporter}, aliases: []string{"TRACING_EXPORTER"}},
+		{key: "tracing.sample_ratio", usage: "share of traces sampled, 0 to 1", value: floatValue{&c.Tracing.SampleRatio}, aliases: []string{"TRACING_SAMPLE_RATIO"}},
+	}
+}
+
+// Load builds the configuration. It registers a flag for every setting,
+// and --config, on flags, parses args with it and then applies, each over
+// the one before: defaults, the config file, the environment and the
+// flags given. All problems found on the way are reported together.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+	cfg.sources = make(map[string]string, len(settings))
+
+	// Flags are parsed first to find --config, but applied last.
+	given := map[string]string{}
+	configFile := flags.String("config", "", "YAML or TOML config file (env "+ConfigFileEnv+")")
+	for _, s := range settings {
+		if s.secret {
+			continue
+		}
+		flags.Func(s.flag(), s.usage+" (env "+s.env()+")", func(v string) error {
+			given[s.key] = v
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	apply := func(s setting, raw, source string) {
+		if err := s.value.Set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q from %s: %v", s.key, raw, source, err))
+			return
+		}
+		cfg.sources[s.key] = source
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		known := make(map[string]bool, len(settings))
+		for _, s := range settings {
+			known[s.key] = true
+			if raw, ok := values[s.key]; ok {
+				apply(s, raw, "file "+path)
+			}
+		}
+		for _, key := range sortedKeys(values) {
+			if !known[key] {
+				errs = append(errs, fmt.Errorf("%s: unknown setting in %s", key, path))
+			}
+		}
+	}
+
+	for _, s := range settings {
+		raw, source, err := lookupEnv(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if source != "" {
+			apply(s, raw, source)
+		}
+	}
+
+	for _, s := range settings {
+		if raw, ok := given[s.key]; ok {
+			apply(s, raw, "flag --"+s.flag())
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// lookupEnv finds the value of s in the environment: the prefixed name
+// first, then the old unprefixed aliases. Secrets may instead be read from
+// the file named by the same variable with a _FILE suffix. Empty variables
+// count as unset, as they always have.
+func lookupEnv(s setting) (value, source string, err error) {
+	for _, name := range append([]string{s.env()}, s.aliases...) {
+		v := os.Getenv(name)
+		ok := v != ""
+		if !s.secret {
+			if ok {
+				return v, "env " + name, nil
+			}
+			continue
+		}
+
+		file := os.Getenv(name + "_FILE")
+		fromFile := file != ""
+		switch {
+		case ok && fromFile:
+			return "", "", fmt.Errorf("%s: both %s and %s_FILE are set", s.key, name, name)
+		case ok:
+			return v, "env " + name, nil
+		case fromFile:
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return "", "", fmt.Errorf("%s: %s_FILE: %w", s.key, name, err)
+			}
+			// Secret files usually end with a newline that is not part of
+			// the secret.
+			return strings.TrimRight(string(data), "\r\n"), "file " + file + " (" + name + "_FILE)", nil
+		}
+	}
+	return "", "", nil
+}
+
+// readFile reads a YAML or TOML config file, told apart by its extension,
+// into values keyed like the settings, e.g. "db.host".
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
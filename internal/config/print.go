@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// redacted replaces the values of secret settings in Print.
+const redacted = "******"
+
+// Print writes every setting with its effective value and where it came
+// from. Secrets are redacted; an unset secret prints as empty so that its
+// absence is still visible.
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range c.settings() {
+		value := s.value.String()
+		if s.secret && value != "" {
+			value = redacted
+		}
+		source := c.sources[s.key]
+		if source == "" {
+			source = "default"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t# %s\n", s.key, value, source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"testTask/pkg/logging"
+)
+
+// Route returns the path of the mux pattern serving r, such as
+// "/questions/{id}", or "" when no pattern matches. The pattern is looked up
+// on mux rather than read from r, as inner middleware may hand the mux a
+// copy of the request.
+func Route(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+// RequestLogger tags every request with an ID, hands the rest of the chain
+// a logger carrying it, and logs the request once it is served.
+func RequestLogger(mux *http.ServeMux, logger *logging.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := Route(mux, r)
+
+			log := logger.Ctx(r.Context()).With(logging.Fields{
+				"request_id": RequestIDFrom(r.Context()),
+				"method":     r.Method,
+				"route":      route,
+			})
+
+			rec := NewResponseRecorder(w)
+			start := time.Now()
+			next.ServeHTTP(rec, r.WithContext(logging.NewContext(r.Context(), log)))
+
+			entry := log.WithFields(logging.Fields{
+				"path":       r.URL.Path,
+				"status":     rec.Status(),
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"bytes":      rec.Bytes(),
+			})
+			switch {
+			case rec.Status() >= http.StatusInternalServerError:
+				entry.Error("request served")
+			case rec.Status() >= http.StatusBadRequest:
+				entry.Warn("request served")
+			default:
+				entry.Info("request served")
+			}
+		}))
+	}
+}
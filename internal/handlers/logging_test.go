@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	logger, err := logging.New(logging.Config{Format: logging.FormatJSON})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	logger.Logger.SetOutput(&buf)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("inside")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("hello"))
+	})
+	h := RequestLogger(mux, logger)(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/things/7", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var inside, served map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &inside))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &served))
+
+	assert.Equal(t, "inside", inside["msg"])
+	assert.Equal(t, "req-1", inside["request_id"])
+	assert.Equal(t, "/things/{id}", inside["route"])
+
+	assert.Equal(t, "request served", served["msg"])
+	assert.Equal(t, "warning", served["level"])
+	assert.Equal(t, "req-1", served["request_id"])
+	assert.Equal(t, "GET", served["method"])
+	assert.Equal(t, "/things/7", served["path"])
+	assert.EqualValues(t, http.StatusTeapot, served["status"])
+	assert.EqualValues(t, 5, served["bytes"])
+	assert.Contains(t, served, "latency_ms")
+}
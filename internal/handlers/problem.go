@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"testTask/internal/pagination"
+	"testTask/internal/validation"
+	"testTask/pkg/logging"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code is a stable
+// identifier clients can switch on; Errors lists field-level failures.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      string                  `json:"code"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []validation.FieldError `json:"errors,omitempty"`
+
+	// Extensions are additional members contributed by the error.
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	b, err := json.Marshal((*plain)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]any, len(p.Extensions))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// ProblemExtender is implemented by errors that add members to the
+// problem they are reported with.
+type ProblemExtender interface {
+	ProblemExtensions() map[string]any
+}
+
+// ErrorMapping ties a domain error to the status and code it is reported
+// with. Field, if set, names the request field the error is about.
+type ErrorMapping struct {
+	Err    error
+	Status int
+	Code   string
+	Field  string
+}
+
+var (
+	ErrInvalidJSON  = errors.New("invalid JSON body")
+	ErrBodyTooLarge = errors.New("request body is too large")
+)
+
+var (
+	mappingsMu sync.RWMutex
+	mappings   = []ErrorMapping{
+		{Err: ErrInvalidJSON, Status: http.StatusBadRequest, Code: "invalid_json"},
+		{Err: ErrBodyTooLarge, Status: http.StatusRequestEntityTooLarge, Code: "body_too_large"},
+		{Err: pagination.ErrInvalidCursor, Status: http.StatusBadRequest, Code: "invalid_cursor", Field: "cursor"},
+		{Err: pagination.ErrInvalidLimit, Status: http.StatusBadRequest, Code: "invalid_limit", Field: "limit"},
+	}
+)
+
+// RegisterErrors adds mappings for a package's domain errors. main calls
+// it before any request is served; the first matching mapping wins, so the
+// order of the calls is the order errors are tried in.
+func RegisterErrors(m ...ErrorMapping) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	mappings = append(mappings, m...)
+}
+
+// ProblemFor maps err onto a problem. Unknown errors become a 500 whose
+// detail does not leak the underlying message.
+func ProblemFor(err error) *Problem {
+	var p *Problem
+
+	var ve *validation.Error
+	if errors.As(err, &ve) {
+		p = newProblem(http.StatusBadRequest, "validation_failed", "request validation failed")
+		p.Errors = ve.Fields
+	} else {
+		p = newProblem(http.StatusInternalServerError, "internal_error", "internal error")
+
+		mappingsMu.RLock()
+		for _, m := range mappings {
+			if errors.Is(err, m.Err) {
+				p = newProblem(m.Status, m.Code, detail(err, m.Err))
+				if m.Field != "" {
+					p.Errors = []validation.FieldError{{Field: m.Field, Code: m.Code, Message: m.Err.Error()}}
+				}
+				break
+			}
+		}
+		mappingsMu.RUnlock()
+	}
+
+	var ext ProblemExtender
+	if errors.As(err, &ext) {
+		p.Extensions = ext.ProblemExtensions()
+	}
+
+	return p
+}
+
+// detail is the message of target, or of a typed error in the chain of
+// err that matches target by its own Is method. Text added by wrapping is
+// left out: it can carry internals such as parser or driver messages.
+func detail(err, target error) string {
+	for e := err; e != nil && e != target; e = errors.Unwrap(e) {
+		if x, ok := e.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return e.Error()
+		}
+	}
+	return target.Error()
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Error writes err as a problem response for r. Server errors are logged
+// together with the request ID the client sees.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	p := ProblemFor(err)
+	p.Instance = r.URL.Path
+	p.RequestID = RequestIDFrom(r.Context())
+
+	if p.Status >= http.StatusInternalServerError {
+		logging.FromContext(r.Context()).Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+
+	WriteProblem(w, p)
+}
+
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	if p.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// PathID parses a positive integer path parameter.
+func PathID(r *http.Request, name string) (uint, error) {
+	id, err := strconv.ParseUint(r.PathValue(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, validation.InvalidField(name, "must be a positive integer")
+	}
+	return uint(id), nil
+}
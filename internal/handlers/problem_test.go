@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"testTask/internal/pagination"
+	"testTask/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestConflict = errors.New("thing already exists")
+
+func init() {
+	RegisterErrors(ErrorMapping{Err: errTestConflict, Status: http.StatusConflict, Code: "thing_exists", Field: "name"})
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+	t.Helper()
+
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+
+	var p Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p
+}
+
+func TestError_RegisteredMapping(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/things", nil)
+	req = req.WithContext(WithRequestID(req.Context(), "req-1"))
+
+	Error(rec, req, fmt.Errorf("create thing: %w", errTestConflict))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, "thing_exists", p.Code)
+	assert.Equal(t, http.StatusConflict, p.Status)
+	assert.Equal(t, "/things", p.Instance)
+	assert.Equal(t, "req-1", p.RequestID)
+	assert.Equal(t, "thing already exists", p.Detail, "wrapping text is not reported")
+	assert.Equal(t, []validation.FieldError{{Field: "name", Code: "thing_exists", Message: "thing already exists"}}, p.Errors)
+}
+
+type thingError struct{ name string }
+
+func (e *thingError) Error() string        { return "thing " + e.name + " already exists" }
+func (e *thingError) Is(target error) bool { return target == errTestConflict }
+
+func TestError_TypedErrorDetail(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Error(rec, httptest.NewRequest(http.MethodPost, "/things", nil), fmt.Errorf("create: %w", &thingError{name: "x"}))
+
+	p := decodeProblem(t, rec)
+	assert.Equal(t, "thing_exists", p.Code)
+	assert.Equal(t, "thing x already exists", p.Detail)
+}
+
+func TestError_Validation(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := &validation.Error{Fields: []validation.FieldError{
+		{Field: "text", Code: "required", Message: "is required"},
+		{Field: "tags", Code: "max", Message: "must have at most 5 items"},
+	}}
+
+	Error(rec, httptest.NewRequest(http.MethodPost, "/questions/", nil), err)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, "validation_failed", p.Code)
+	assert.Len(t, p.Errors, 2)
+}
+
+func TestError_BuiltinMappings(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Error(rec, httptest.NewRequest(http.MethodGet, "/questions/", nil), pagination.ErrInvalidCursor)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_cursor", decodeProblem(t, rec).Code)
+}
+
+func TestError_UnknownIsInternal(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Error(rec, httptest.NewRequest(http.MethodGet, "/questions/", nil), errors.New("pq: connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, "internal_error", p.Code)
+	assert.NotContains(t, rec.Body.String(), "connection refused")
+}
+
+func TestPathID(t *testing.T) {
+	for value, ok := range map[string]bool{"7": true, "0": false, "-1": false, "x": false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetPathValue("id", value)
+
+		id, err := PathID(req, "id")
+		if ok {
+			require.NoError(t, err)
+			assert.Equal(t, uint(7), id)
+		} else {
+			var ve *validation.Error
+			assert.True(t, errors.As(err, &ve), value)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "client-id.1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "client-id.1", seen)
+	assert.Equal(t, "client-id.1", rec.Header().Get(RequestIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+}
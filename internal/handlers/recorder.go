@@ -0,0 +1,44 @@
+package handlers
+
+import "net/http"
+
+// ResponseRecorder remembers the status code and body size of a response
+// for middleware that reports on it.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *ResponseRecorder) Status() int {
+	return r.status
+}
+
+func (r *ResponseRecorder) Bytes() int {
+	return r.bytes
+}
+
+func (r *ResponseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,9 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+
+	"testTask/internal/validation"
 )
 
+// MaxBodyBytes caps the size of JSON request bodies.
+var MaxBodyBytes int64 = 1 << 20
+
 func WriteJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -14,10 +23,49 @@ func WriteJSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
-func WriteError(w http.ResponseWriter, status int, msg string) {
-	WriteJSON(w, status, map[string]string{"error": msg})
+// ReadJSON decodes a single JSON value from the request body into dst.
+// Bodies over MaxBodyBytes fail with ErrBodyTooLarge, fields dst does not
+// know with a *validation.Error and anything else malformed with
+// ErrInvalidJSON.
+func ReadJSON(r *http.Request, dst any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return decodeError(err)
+		}
+		return fmt.Errorf("%w: unexpected data after the JSON value", ErrInvalidJSON)
+	}
+	return nil
 }
 
-func ReadJSON(r *http.Request, dst any) error {
-	return json.NewDecoder(r.Body).Decode(dst)
+func decodeError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, tooLarge.Limit)
+	}
+
+	// encoding/json reports unknown fields only by message.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &validation.Error{Fields: []validation.FieldError{{
+			Field:   strings.Trim(field, `"`),
+			Code:    "unknown",
+			Message: "is not a known field",
+		}}}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &validation.Error{Fields: []validation.FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be of type " + typeErr.Type.String(),
+		}}}
+	}
+
+	return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"testTask/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type body struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+func readBody(s string) (body, error) {
+	var b body
+	err := ReadJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(s)), &b)
+	return b, err
+}
+
+func TestReadJSON(t *testing.T) {
+	b, err := readBody(`{"text":"hi","count":2}`)
+	require.NoError(t, err)
+	assert.Equal(t, body{Text: "hi", Count: 2}, b)
+
+	_, err = readBody(`{"text":"hi","extra":1}`)
+	var ve *validation.Error
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "extra", ve.Fields[0].Field)
+	assert.Equal(t, "unknown", ve.Fields[0].Code)
+
+	_, err = readBody(`{"count":"two"}`)
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "count", ve.Fields[0].Field)
+
+	_, err = readBody(`{"text":"a"} {"text":"b"}`)
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+
+	_, err = readBody(`{"text":`)
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+}
+
+// decodeError recognizes unknown fields by this message alone, so a change
+// to it in encoding/json has to fail here rather than turn them into 400
+// invalid_json.
+func TestDecodeError_UnknownFieldMessage(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"extra":1}`))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&body{})
+	require.Error(t, err)
+	assert.Equal(t, `json: unknown field "extra"`, err.Error())
+}
+
+func TestReadJSON_TooLarge(t *testing.T) {
+	old := MaxBodyBytes
+	MaxBodyBytes = 16
+	defer func() { MaxBodyBytes = old }()
+
+	_, err := readBody(`{"text":"` + strings.Repeat("x", 64) + `"}`)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, ProblemFor(err).Status)
+}
@@ -0,0 +1,101 @@
+// Package health serves the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"testTask/internal/handlers"
+	"testTask/pkg/logging"
+)
+
+// Check reports whether one dependency of the service is usable.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Handler answers /healthz while the process is up and /readyz while it
+// is not shutting down and every check passes within the timeout.
+type Handler struct {
+	checks  []Check
+	timeout time.Duration
+	ready   atomic.Bool
+}
+
+func NewHandler(timeout time.Duration, checks ...Check) *Handler {
+	h := &Handler{checks: checks, timeout: timeout}
+	h.ready.Store(true)
+	return h
+}
+
+func (h *Handler) Register(router *http.ServeMux) {
+	router.HandleFunc("GET /healthz", h.Live)
+	router.HandleFunc("GET /readyz", h.Ready)
+}
+
+// Drain makes /readyz fail from now on, so load balancers stop sending
+// traffic before the server shuts down.
+func (h *Handler) Drain() {
+	h.ready.Store(false)
+}
+
+// Report is the body of both probes. Checks maps each check to "ok" or
+// "failing"; the reason a check failed is only logged, since the probes
+// are served without authentication. Errors that implement StatusReporter
+// replace "failing" with their own status.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// StatusReporter is implemented by check errors that have a status safe
+// to show to anyone, such as a count of pending migrations.
+type StatusReporter interface {
+	ReadinessStatus() string
+}
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+	statusFailing     = "failing"
+)
+
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	handlers.WriteJSON(w, http.StatusOK, Report{Status: statusOK})
+}
+
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		handlers.WriteJSON(w, http.StatusServiceUnavailable, Report{Status: statusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	report := Report{Status: statusOK, Checks: make(map[string]string, len(h.checks))}
+	for _, c := range h.checks {
+		if err := c.Check(ctx); err != nil {
+			logging.FromContext(r.Context()).Warnf("readiness check %s failed: %v", c.Name, err)
+			report.Status = statusUnavailable
+			report.Checks[c.Name] = statusFailing
+			var sr StatusReporter
+			if errors.As(err, &sr) {
+				report.Checks[c.Name] = sr.ReadinessStatus()
+			}
+			continue
+		}
+		report.Checks[c.Name] = statusOK
+	}
+
+	code := http.StatusOK
+	if report.Status != statusOK {
+		code = http.StatusServiceUnavailable
+	}
+	handlers.WriteJSON(w, code, report)
+}
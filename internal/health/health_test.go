@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, h *Handler, path string) (int, Report) {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func ok(name string) Check {
+	return Check{Name: name, Check: func(context.Context) error { return nil }}
+}
+
+func TestReady_AllChecksPass(t *testing.T) {
+	h := NewHandler(time.Second, ok("postgres"), ok("migrations"))
+
+	code, report := serve(t, h, "/readyz")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Report{Status: "ok", Checks: map[string]string{"postgres": "ok", "migrations": "ok"}}, report)
+}
+
+func TestReady_FailingCheck(t *testing.T) {
+	failing := Check{Name: "migrations", Check: func(context.Context) error {
+		return errors.New("2 pending migrations")
+	}}
+	h := NewHandler(time.Second, ok("postgres"), failing)
+
+	code, report := serve(t, h, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, "failing", report.Checks["migrations"], "the reason is not exposed")
+	assert.Equal(t, "ok", report.Checks["postgres"])
+}
+
+func TestReady_PendingMigrations(t *testing.T) {
+	pending := Check{Name: "migrations", Check: func(context.Context) error {
+		return fmt.Errorf("check: %w", &PendingMigrationsError{Count: 2})
+	}}
+	h := NewHandler(time.Second, ok("postgres"), pending)
+
+	code, report := serve(t, h, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "2 pending migrations", report.Checks["migrations"])
+}
+
+func TestReady_Timeout(t *testing.T) {
+	slow := Check{Name: "postgres", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	h := NewHandler(10*time.Millisecond, slow)
+
+	code, report := serve(t, h, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failing", report.Checks["postgres"])
+}
+
+func TestDrain(t *testing.T) {
+	called := false
+	check := Check{Name: "postgres", Check: func(context.Context) error {
+		called = true
+		return nil
+	}}
+	h := NewHandler(time.Second, check)
+	h.Drain()
+
+	code, report := serve(t, h, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "draining", report.Status)
+	assert.False(t, called)
+
+	code, report = serve(t, h, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", report.Status)
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+// Migrations fails while provider knows migrations not yet applied.
+func Migrations(provider *goose.Provider) Check {
+	return Check{
+		Name: "migrations",
+		Check: func(ctx context.Context) error {
+			statuses, err := provider.Status(ctx)
+			if err != nil {
+				return err
+			}
+			pending := 0
+			for _, s := range statuses {
+				if s.State == goose.StatePending {
+					pending++
+				}
+			}
+			if pending > 0 {
+				return &PendingMigrationsError{Count: pending}
+			}
+			return nil
+		},
+	}
+}
+
+// PendingMigrationsError fails the migrations check. The count is shown on
+// /readyz, unlike the text of other check errors.
+type PendingMigrationsError struct {
+	Count int
+}
+
+func (e *PendingMigrationsError) Error() string {
+	return fmt.Sprintf("%d pending migrations", e.Count)
+}
+
+func (e *PendingMigrationsError) ReadinessStatus() string {
+	return e.Error()
+}
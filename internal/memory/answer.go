@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/comment"
+	"testTask/internal/trash"
+
+	"gorm.io/gorm"
+)
+
+type answers struct {
+	*Store
+}
+
+func (s *answers) Create(ctx context.Context, a *answer.Answer) (*answer.Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The foreign key only needs the question row, deleted or not.
+	if _, ok := s.questions[a.QuestionID]; !ok {
+		return nil, answer.ErrQuestionNotFound
+	}
+	if a.Exclusive && s.hasExclusive(a.QuestionID, a.UserID, 0) {
+		return nil, answer.ErrAlreadyAnswered
+	}
+
+	s.seq.answer++
+	a.ID = s.seq.answer
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	stored := *a
+	stored.Accepted = false
+	s.answers[a.ID] = &stored
+
+	s.addRevision(a.ID, a.Text, a.UserID)
+	return a, nil
+}
+
+func (s *answers) HasAnswered(ctx context.Context, questionID uint, userID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, a := range s.answers {
+		if a.QuestionID == questionID && a.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addRevision appends a revision. The caller holds the write lock.
+func (s *answers) addRevision(answerID uint, text, authorID string) {
+	s.seq.answerRevision++
+	s.answerRevisions = append(s.answerRevisions, answer.Revision{
+		ID:        s.seq.answerRevision,
+		AnswerID:  answerID,
+		Text:      text,
+		AuthorID:  authorID,
+		CreatedAt: now(),
+	})
+}
+
+func (s *answers) FindOne(ctx context.Context, id uint) (*answer.Answer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.answers[id]
+	if !ok || a.DeletedAt.Valid {
+		return nil, nil
+	}
+	return s.answerCopy(a), nil
+}
+
+func (s *answers) Update(ctx context.Context, a *answer.Answer, authorID string) (*answer.Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a.UpdatedAt = now()
+	if stored, ok := s.answers[a.ID]; ok && !stored.DeletedAt.Valid {
+		stored.Text = a.Text
+		stored.UpdatedAt = a.UpdatedAt
+	}
+	s.addRevision(a.ID, a.Text, authorID)
+	return a, nil
+}
+
+func (s *answers) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.withdrawAcceptance(id)
+	if a, ok := s.answers[id]; ok && !a.DeletedAt.Valid {
+		// Comments share the answer's deleted_at so Restore brings back
+		// only those deleted with it.
+		a.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+		s.deleteComments(comment.TargetAnswer, id, a.DeletedAt)
+	}
+	return nil
+}
+
+func (s *answers) FindByQuestion(ctx context.Context, questionID uint, filter answer.ListFilter) ([]answer.Answer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []answer.Answer
+	for _, a := range s.liveAnswers(questionID) {
+		if a.ID == filter.ExcludeID {
+			continue
+		}
+		if after := filter.After; after != nil {
+			if filter.Sort == answer.SortScore {
+				if !(a.Score < after.Score || (a.Score == after.Score && a.ID > after.ID)) {
+					continue
+				}
+			} else if !(a.CreatedAt.After(after.Time) || (a.CreatedAt.Equal(after.Time) && a.ID > after.ID)) {
+				continue
+			}
+		}
+		list = append(list, *s.answerCopy(a))
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if filter.Sort == answer.SortScore && a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if filter.Sort != answer.SortScore && !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return limit(list, filter.Limit), nil
+}
+
+func (s *answers) FindAccepted(ctx context.Context, questionID uint) (*answer.Answer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, ok := s.questions[questionID]
+	if !ok || q.AcceptedAnswerID == nil {
+		return nil, nil
+	}
+	a, ok := s.answers[*q.AcceptedAnswerID]
+	if !ok || a.DeletedAt.Valid {
+		return nil, nil
+	}
+	return s.answerCopy(a), nil
+}
+
+func (s *answers) FindRevisions(ctx context.Context, answerID uint) ([]answer.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []answer.Revision{}
+	for _, r := range s.answerRevisions {
+		if r.AnswerID == answerID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (s *answers) FindRevision(ctx context.Context, answerID, revisionID uint) (*answer.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.answerRevisions {
+		if r.ID == revisionID && r.AnswerID == answerID {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *answers) FindDeleted(ctx context.Context, filter trash.Filter) ([]answer.Answer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []answer.Answer
+	for _, a := range s.answers {
+		if !a.DeletedAt.Valid {
+			continue
+		}
+		if after := filter.After; after != nil && !deletedBefore(a.DeletedAt.Time, a.ID, after.DeletedAt, after.ID) {
+			continue
+		}
+		list = append(list, *a)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return deletedBefore(list[j].DeletedAt.Time, list[j].ID, list[i].DeletedAt.Time, list[i].ID)
+	})
+
+	return limit(list, filter.Limit), nil
+}
+
+func (s *answers) FindOneDeleted(ctx context.Context, id uint) (*answer.Answer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.answers[id]
+	if !ok || !a.DeletedAt.Valid {
+		return nil, nil
+	}
+	c := *a
+	return &c, nil
+}
+
+func (s *answers) Restore(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.answers[id]
+	if !ok {
+		return answer.ErrQuestionDeleted
+	}
+	if q, ok := s.questions[a.QuestionID]; !ok || q.DeletedAt.Valid {
+		return answer.ErrQuestionDeleted
+	}
+	if !a.DeletedAt.Valid {
+		return nil
+	}
+	if a.Exclusive && s.hasExclusive(a.QuestionID, a.UserID, a.ID) {
+		return answer.ErrAlreadyAnswered
+	}
+
+	s.restoreComments(comment.TargetAnswer, id, a.DeletedAt)
+	a.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (s *answers) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, a := range s.answers {
+		if a.DeletedAt.Valid && a.DeletedAt.Time.Before(before) {
+			s.purgeAnswer(id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// deletedBefore reports whether a row deleted at (t, id) comes after the
+// row at (afterTime, afterID) in the trash, newest first.
+func deletedBefore(t time.Time, id uint, afterTime time.Time, afterID uint) bool {
+	return t.Before(afterTime) || (t.Equal(afterTime) && id < afterID)
+}
+
+// limit cuts list down to n rows.
+func limit[T any](list []T, n int) []T {
+	if n > 0 && len(list) > n {
+		return list[:n]
+	}
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
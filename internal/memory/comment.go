@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"testTask/internal/comment"
+
+	"gorm.io/gorm"
+)
+
+type comments struct {
+	*Store
+}
+
+// liveTarget reports whether the question or answer is live. The caller
+// holds the lock.
+func (s *comments) liveTarget(target comment.Target, id uint) bool {
+	switch target {
+	case comment.TargetQuestion:
+		q, ok := s.questions[id]
+		return ok && !q.DeletedAt.Valid
+	case comment.TargetAnswer:
+		a, ok := s.answers[id]
+		return ok && !a.DeletedAt.Valid
+	}
+	return false
+}
+
+func (s *comments) Create(ctx context.Context, c *comment.Comment) (*comment.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveTarget(c.Target()) {
+		return nil, comment.ErrTargetNotFound
+	}
+
+	s.seq.comment++
+	c.ID = s.seq.comment
+	c.CreatedAt = now()
+	c.UpdatedAt = c.CreatedAt
+	stored := *c
+	s.comments[c.ID] = &stored
+	return c, nil
+}
+
+func (s *comments) FindOne(ctx context.Context, id uint) (*comment.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.comments[id]
+	if !ok || c.DeletedAt.Valid {
+		return nil, nil
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (s *comments) FindByTarget(ctx context.Context, target comment.Target, targetID uint, filter comment.ListFilter) ([]comment.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveTarget(target, targetID) {
+		return nil, comment.ErrTargetNotFound
+	}
+
+	var list []comment.Comment
+	for _, c := range s.comments {
+		if t, id := c.Target(); t != target || id != targetID || c.DeletedAt.Valid {
+			continue
+		}
+		if after := filter.After; after != nil &&
+			!(c.CreatedAt.After(after.Time) || (c.CreatedAt.Equal(after.Time) && c.ID > after.ID)) {
+			continue
+		}
+		list = append(list, *c)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return limit(list, filter.Limit), nil
+}
+
+func (s *comments) Update(ctx context.Context, c *comment.Comment) (*comment.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.UpdatedAt = now()
+	if stored, ok := s.comments[c.ID]; ok && !stored.DeletedAt.Valid {
+		stored.Text = c.Text
+		stored.UpdatedAt = c.UpdatedAt
+	}
+	return c, nil
+}
+
+func (s *comments) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.comments[id]; ok && !c.DeletedAt.Valid {
+		c.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	}
+	return nil
+}
+
+func (s *comments) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, c := range s.comments {
+		if c.DeletedAt.Valid && c.DeletedAt.Time.Before(before) {
+			delete(s.comments, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/comment"
+	"testTask/internal/question"
+	"testTask/internal/search"
+	"testTask/internal/storagetest"
+	"testTask/internal/vote"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorages(t *testing.T) (question.Storage, answer.Storage) {
+	store := NewStore()
+	return store.Questions(), store.Answers()
+}
+
+func TestQuestionStorage(t *testing.T) {
+	storagetest.TestQuestionStorage(t, newStorages)
+}
+
+func TestAnswerStorage(t *testing.T) {
+	storagetest.TestAnswerStorage(t, newStorages)
+}
+
+func TestCommentsFollowTheirPost(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	questions, answers, comments := store.Questions(), store.Answers(), store.Comments()
+
+	q, err := questions.Create(ctx, &question.Question{Text: "q", AuthorID: "alice"})
+	require.NoError(t, err)
+	a, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "a"})
+	require.NoError(t, err)
+
+	onQuestion, err := comments.Create(ctx, &comment.Comment{QuestionID: &q.ID, UserID: "carol", Text: "c1"})
+	require.NoError(t, err)
+	onAnswer, err := comments.Create(ctx, &comment.Comment{AnswerID: &a.ID, UserID: "carol", Text: "c2"})
+	require.NoError(t, err)
+	removed, err := comments.Create(ctx, &comment.Comment{QuestionID: &q.ID, UserID: "dave", Text: "c3"})
+	require.NoError(t, err)
+	require.NoError(t, comments.Delete(ctx, removed.ID))
+
+	list, err := comments.FindByTarget(ctx, comment.TargetQuestion, q.ID, comment.ListFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, onQuestion.ID, list[0].ID)
+
+	require.NoError(t, questions.Delete(ctx, q.ID))
+	_, err = comments.FindByTarget(ctx, comment.TargetAnswer, a.ID, comment.ListFilter{Limit: 10})
+	assert.ErrorIs(t, err, comment.ErrTargetNotFound)
+	_, err = comments.Create(ctx, &comment.Comment{QuestionID: &q.ID, UserID: "carol", Text: "late"})
+	assert.ErrorIs(t, err, comment.ErrTargetNotFound)
+
+	require.NoError(t, questions.Restore(ctx, q.ID))
+	got, err := comments.FindOne(ctx, onAnswer.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got, "deleted with the question, restored with it")
+	got, err = comments.FindOne(ctx, removed.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "deleted on its own earlier, stays deleted")
+
+	require.NoError(t, questions.Delete(ctx, q.ID))
+	n, err := questions.Purge(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = comments.Purge(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "purged together with the question")
+}
+
+func TestVotes(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	questions, answers, votes := store.Questions(), store.Answers(), store.Votes()
+
+	q, err := questions.Create(ctx, &question.Question{Text: "q", AuthorID: "alice"})
+	require.NoError(t, err)
+	a, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "a"})
+	require.NoError(t, err)
+
+	score, err := votes.Cast(ctx, vote.TargetAnswer, a.ID, "carol", vote.Up)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), score)
+	score, err = votes.Cast(ctx, vote.TargetAnswer, a.ID, "dave", vote.Up)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), score)
+	score, err = votes.Cast(ctx, vote.TargetAnswer, a.ID, "carol", vote.Down)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), score)
+	score, err = votes.Cast(ctx, vote.TargetAnswer, a.ID, "dave", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), score)
+
+	got, err := answers.FindOne(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), got.Score)
+
+	require.NoError(t, answers.Delete(ctx, a.ID))
+	_, err = votes.Cast(ctx, vote.TargetAnswer, a.ID, "carol", vote.Up)
+	assert.ErrorIs(t, err, vote.ErrNotFound)
+	_, err = votes.Cast(ctx, vote.TargetQuestion, 999, "carol", vote.Up)
+	assert.ErrorIs(t, err, vote.ErrNotFound)
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	questions, answers := store.Questions(), store.Answers()
+
+	q, err := questions.Create(ctx, &question.Question{Text: "How do goroutines work?", AuthorID: "alice"})
+	require.NoError(t, err)
+	a, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "Goroutines are cheap"})
+	require.NoError(t, err)
+	gone, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "carol", Text: "goroutines"})
+	require.NoError(t, err)
+	require.NoError(t, answers.Delete(ctx, gone.ID))
+
+	hits, err := store.Index().Search(ctx, search.Query{Text: "goroutines", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.ElementsMatch(t, []search.Kind{search.KindQuestion, search.KindAnswer}, []search.Kind{hits[0].Kind, hits[1].Kind})
+	for _, h := range hits {
+		assert.Equal(t, q.ID, h.QuestionID)
+		if h.Kind == search.KindAnswer {
+			assert.Equal(t, a.ID, h.ID)
+		}
+	}
+}
@@ -0,0 +1,596 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/comment"
+	"testTask/internal/question"
+	"testTask/internal/trash"
+	"testTask/internal/vote"
+
+	"gorm.io/gorm"
+)
+
+type questions struct {
+	*Store
+}
+
+func (s *questions) Create(ctx context.Context, q *question.Question) (*question.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq.question++
+	q.ID = s.seq.question
+	q.CreatedAt = now()
+	q.UpdatedAt = q.CreatedAt
+	if q.Status == "" {
+		q.Status = question.StatusOpen
+	}
+	if q.AnswerPolicy == "" {
+		q.AnswerPolicy = answer.DefaultPolicy
+	}
+
+	stored := *q
+	stored.Tags, stored.Answers, stored.AcceptedAnswer = nil, nil, nil
+	s.questions[q.ID] = &stored
+	q.Tags = s.setTags(q.ID, question.TagNames(q.Tags))
+	s.addRevision(q.ID, q.Text, question.TagNames(q.Tags), q.AuthorID)
+	q.LastActivityAt = q.CreatedAt
+	return q, nil
+}
+
+// addRevision appends a revision. The caller holds the write lock.
+func (s *questions) addRevision(questionID uint, text string, tags []string, authorID string) {
+	s.seq.questionRevision++
+	s.questionRevisions = append(s.questionRevisions, question.Revision{
+		ID:         s.seq.questionRevision,
+		QuestionID: questionID,
+		Text:       text,
+		Tags:       tags,
+		AuthorID:   authorID,
+		CreatedAt:  now(),
+	})
+}
+
+// live returns a live question. The caller holds the lock.
+func (s *questions) live(id uint) (*question.Question, bool) {
+	q, ok := s.questions[id]
+	if !ok || q.DeletedAt.Valid {
+		return nil, false
+	}
+	return q, true
+}
+
+// withStats returns a copy of q with the derived fields the Postgres
+// storage selects: tags, answer count, last activity and accepted answer.
+// The caller holds the lock.
+func (s *questions) withStats(q *question.Question) question.Question {
+	c := *q
+	c.Tags = s.tagsOf(q.ID)
+
+	answers := s.liveAnswers(q.ID)
+	c.AnswerCount = int64(len(answers))
+	c.LastActivityAt = q.CreatedAt
+	for _, a := range answers {
+		if a.CreatedAt.After(c.LastActivityAt) {
+			c.LastActivityAt = a.CreatedAt
+		}
+	}
+
+	if q.AcceptedAnswerID != nil {
+		if a, ok := s.answers[*q.AcceptedAnswerID]; ok && !a.DeletedAt.Valid {
+			c.AcceptedAnswer = s.answerCopy(a)
+		}
+	}
+	return c
+}
+
+func (s *questions) FindOne(ctx context.Context, id uint) (*question.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, ok := s.live(id)
+	if !ok {
+		return nil, nil
+	}
+	c := s.withStats(q)
+	return &c, nil
+}
+
+func (s *questions) FindOneWithAnswers(ctx context.Context, id uint) (*question.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, ok := s.live(id)
+	if !ok {
+		return nil, nil
+	}
+	c := s.withStats(q)
+
+	c.Answers = []answer.Answer{}
+	for _, a := range s.liveAnswers(id) {
+		c.Answers = append(c.Answers, *s.answerCopy(a))
+	}
+	sort.Slice(c.Answers, func(i, j int) bool {
+		a, b := c.Answers[i], c.Answers[j]
+		if a.Accepted != b.Accepted {
+			return a.Accepted
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return &c, nil
+}
+
+func (s *questions) FindAll(ctx context.Context, filter question.ListFilter) ([]question.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// compare orders two questions by the sort key, then by ID.
+	compare := func(a, b question.Question) int {
+		var c int
+		switch filter.Sort {
+		case question.SortAnswerCount:
+			c = cmpInt(a.AnswerCount, b.AnswerCount)
+		case question.SortLastActivity:
+			c = a.LastActivityAt.Compare(b.LastActivityAt)
+		default:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmpInt(int64(a.ID), int64(b.ID))
+		}
+		if filter.Order != question.OrderAsc {
+			c = -c
+		}
+		return c
+	}
+
+	var cursor *question.Question
+	if after := filter.After; after != nil {
+		cursor = &question.Question{
+			ID:             after.ID,
+			CreatedAt:      after.Time,
+			LastActivityAt: after.Time,
+			AnswerCount:    after.Count,
+		}
+	}
+
+	list := []question.Question{}
+	for _, stored := range s.questions {
+		if stored.DeletedAt.Valid {
+			continue
+		}
+		q := s.withStats(stored)
+
+		if filter.CreatedBefore != nil && !q.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		if filter.CreatedAfter != nil && !q.CreatedAt.After(*filter.CreatedAfter) {
+			continue
+		}
+		if len(filter.Tags) > 0 && !taggedWith(q.Tags, filter.Tags, filter.TagMode) {
+			continue
+		}
+		if filter.Solved != nil && *filter.Solved != (q.AcceptedAnswerID != nil) {
+			continue
+		}
+		if filter.HasAnswers != nil && *filter.HasAnswers != (q.AnswerCount > 0) {
+			continue
+		}
+		if cursor != nil && compare(q, *cursor) <= 0 {
+			continue
+		}
+		list = append(list, q)
+	}
+
+	slices.SortFunc(list, compare)
+	return limit(list, filter.Limit), nil
+}
+
+func taggedWith(tags []question.Tag, names []string, mode question.TagMode) bool {
+	matched := 0
+	for _, t := range tags {
+		if slices.Contains(names, t.Name) {
+			matched++
+		}
+	}
+	if mode == question.TagModeAny {
+		return matched > 0
+	}
+	return matched == len(names)
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (s *questions) SetAcceptedAnswer(ctx context.Context, questionID uint, answerID *uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.live(questionID)
+	if answerID != nil {
+		a, found := s.answers[*answerID]
+		if !ok || !found || a.QuestionID != questionID || a.DeletedAt.Valid {
+			return question.ErrAnswerNotOnQuestion
+		}
+	}
+	if ok {
+		q.AcceptedAnswerID = answerID
+	}
+	return nil
+}
+
+func (s *questions) Update(ctx context.Context, q *question.Question, editorID string, tags *[]string) (*question.Question, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.live(q.ID)
+	if !ok {
+		return nil, fmt.Errorf("update question: question id=%d not found", q.ID)
+	}
+
+	before := question.TagNames(s.tagsOf(q.ID))
+	q.Tags = s.tagsOf(q.ID)
+	if tags != nil {
+		q.Tags = s.setTags(q.ID, *tags)
+	}
+	after := question.TagNames(q.Tags)
+
+	if q.AnswerPolicy != stored.AnswerPolicy && q.AnswerPolicy.Exclusive() {
+		s.claimExclusive(q.ID)
+	}
+
+	q.UpdatedAt = now()
+	changed := stored.Text != q.Text || !slices.Equal(before, after)
+	stored.Text = q.Text
+	stored.AnswerPolicy = q.AnswerPolicy
+	stored.UpdatedAt = q.UpdatedAt
+	if changed {
+		s.addRevision(q.ID, q.Text, after, editorID)
+	}
+	return q, nil
+}
+
+// claimExclusive makes the oldest live answer of every user without an
+// exclusive one exclusive, as the Postgres storage does when a question
+// switches to a single-answer policy. The caller holds the write lock.
+func (s *questions) claimExclusive(questionID uint) {
+	oldest := map[string]*answer.Answer{}
+	claimed := map[string]bool{}
+	for _, a := range s.liveAnswers(questionID) {
+		if a.Exclusive {
+			claimed[a.UserID] = true
+		}
+		if o, ok := oldest[a.UserID]; !ok || a.ID < o.ID {
+			oldest[a.UserID] = a
+		}
+	}
+	for userID, a := range oldest {
+		if !claimed[userID] {
+			a.Exclusive = true
+		}
+	}
+}
+
+// setTags replaces the tags of a question, creating missing ones. The
+// caller holds the write lock.
+func (s *questions) setTags(questionID uint, names []string) []question.Tag {
+	links := make(map[uint]bool, len(names))
+	for _, name := range names {
+		t := s.tagByName(name)
+		if t == nil {
+			s.seq.tag++
+			t = &question.Tag{ID: s.seq.tag, Name: name, CreatedAt: now()}
+			s.tags[t.ID] = t
+		}
+		links[t.ID] = true
+	}
+	s.questionTags[questionID] = links
+	return s.tagsOf(questionID)
+}
+
+// tagsOf returns the tags of a question by name. The caller holds the lock.
+func (s *questions) tagsOf(questionID uint) []question.Tag {
+	tags := []question.Tag{}
+	for id := range s.questionTags[questionID] {
+		tags = append(tags, *s.tags[id])
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// tagByName returns nil if there is no such tag. The caller holds the lock.
+func (s *questions) tagByName(name string) *question.Tag {
+	for _, t := range s.tags {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *questions) FindTags(ctx context.Context) ([]question.TagUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[uint]int64, len(s.tags))
+	for questionID, links := range s.questionTags {
+		if _, ok := s.live(questionID); !ok {
+			continue
+		}
+		for id := range links {
+			counts[id]++
+		}
+	}
+
+	list := make([]question.TagUsage, 0, len(s.tags))
+	for id, t := range s.tags {
+		list = append(list, question.TagUsage{Name: t.Name, Count: counts[id]})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+func (s *questions) FindTag(ctx context.Context, name string) (*question.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := s.tagByName(name)
+	if t == nil {
+		return nil, nil
+	}
+	c := *t
+	return &c, nil
+}
+
+func (s *questions) RenameTag(ctx context.Context, id uint, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if other := s.tagByName(name); other != nil && other.ID != id {
+		return fmt.Errorf("rename tag: %w", gorm.ErrDuplicatedKey)
+	}
+	if t, ok := s.tags[id]; ok {
+		t.Name = name
+	}
+	return nil
+}
+
+func (s *questions) MergeTags(ctx context.Context, fromID, intoID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, links := range s.questionTags {
+		if links[fromID] {
+			delete(links, fromID)
+			links[intoID] = true
+		}
+	}
+	delete(s.tags, fromID)
+	return nil
+}
+
+func (s *questions) ChangeStatus(ctx context.Context, change *question.StatusChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.live(change.QuestionID)
+	if !ok || q.Status != change.From {
+		return question.ErrInvalidTransition
+	}
+	q.Status = change.To
+	q.UpdatedAt = now()
+	s.addStatusChange(change)
+	return nil
+}
+
+// addStatusChange records a change. The caller holds the write lock.
+func (s *questions) addStatusChange(change *question.StatusChange) {
+	s.seq.statusChange++
+	change.ID = s.seq.statusChange
+	change.CreatedAt = now()
+	s.statusChanges = append(s.statusChanges, *change)
+}
+
+func (s *questions) FindStatusChanges(ctx context.Context, questionID uint) ([]question.StatusChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []question.StatusChange{}
+	for _, c := range s.statusChanges {
+		if c.QuestionID == questionID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (s *questions) ArchiveInactive(ctx context.Context, from []question.Status, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Questions are archived in ID order so the history reads the same
+	// on every run.
+	ids := make([]uint, 0, len(s.questions))
+	for id := range s.questions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var n int64
+	for _, id := range ids {
+		q, ok := s.live(id)
+		if !ok || !slices.Contains(from, q.Status) || !s.withStats(q).LastActivityAt.Before(before) {
+			continue
+		}
+		s.addStatusChange(&question.StatusChange{
+			QuestionID: id,
+			From:       q.Status,
+			To:         question.StatusArchived,
+			Reason:     question.ArchiveReason,
+		})
+		q.Status = question.StatusArchived
+		q.UpdatedAt = now()
+		n++
+	}
+	return n, nil
+}
+
+func (s *questions) FindRevisions(ctx context.Context, questionID uint) ([]question.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []question.Revision{}
+	for _, r := range s.questionRevisions {
+		if r.QuestionID == questionID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (s *questions) FindRevision(ctx context.Context, questionID, revisionID uint) (*question.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.questionRevisions {
+		if r.ID == revisionID && r.QuestionID == questionID {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *questions) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.live(id)
+	if !ok {
+		return nil
+	}
+
+	// Answers and comments share the question's deleted_at so Restore can
+	// tell them apart from those deleted on their own earlier.
+	deleted := gorm.DeletedAt{Time: now(), Valid: true}
+	q.DeletedAt = deleted
+	for _, a := range s.liveAnswers(id) {
+		a.DeletedAt = deleted
+	}
+	s.deleteComments(comment.TargetQuestion, id, deleted)
+	for _, a := range s.answers {
+		if a.QuestionID == id {
+			s.deleteComments(comment.TargetAnswer, a.ID, deleted)
+		}
+	}
+	return nil
+}
+
+func (s *questions) FindDeleted(ctx context.Context, filter trash.Filter) ([]question.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []question.Question{}
+	for _, q := range s.questions {
+		if !q.DeletedAt.Valid {
+			continue
+		}
+		if after := filter.After; after != nil && !deletedBefore(q.DeletedAt.Time, q.ID, after.DeletedAt, after.ID) {
+			continue
+		}
+		list = append(list, *q)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return deletedBefore(list[j].DeletedAt.Time, list[j].ID, list[i].DeletedAt.Time, list[i].ID)
+	})
+	return limit(list, filter.Limit), nil
+}
+
+func (s *questions) FindOneDeleted(ctx context.Context, id uint) (*question.Question, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, ok := s.questions[id]
+	if !ok || !q.DeletedAt.Valid {
+		return nil, nil
+	}
+	c := *q
+	return &c, nil
+}
+
+func (s *questions) Restore(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.questions[id]
+	if !ok {
+		return fmt.Errorf("restore question: %w", gorm.ErrRecordNotFound)
+	}
+	if !q.DeletedAt.Valid {
+		return nil
+	}
+
+	for _, a := range s.answers {
+		if a.QuestionID != id {
+			continue
+		}
+		s.restoreComments(comment.TargetAnswer, a.ID, q.DeletedAt)
+		if a.DeletedAt.Valid && a.DeletedAt.Time.Equal(q.DeletedAt.Time) {
+			a.DeletedAt = gorm.DeletedAt{}
+		}
+	}
+	s.restoreComments(comment.TargetQuestion, id, q.DeletedAt)
+	q.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (s *questions) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, q := range s.questions {
+		if !q.DeletedAt.Valid || !q.DeletedAt.Time.Before(before) {
+			continue
+		}
+
+		// Mirrors ON DELETE CASCADE on everything referencing the question.
+		for answerID, a := range s.answers {
+			if a.QuestionID == id {
+				s.purgeAnswer(answerID)
+			}
+		}
+		s.questionRevisions = slices.DeleteFunc(s.questionRevisions, func(r question.Revision) bool {
+			return r.QuestionID == id
+		})
+		s.statusChanges = slices.DeleteFunc(s.statusChanges, func(c question.StatusChange) bool {
+			return c.QuestionID == id
+		})
+		s.purgeTarget(comment.TargetQuestion, vote.TargetQuestion, id)
+		delete(s.questionTags, id)
+		delete(s.questions, id)
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+
+	"testTask/internal/search"
+)
+
+type index struct {
+	*Store
+}
+
+// Search copies the live questions and answers into a search.MemoryIndex
+// and queries it, so that the index never lags behind the store.
+func (s *index) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	idx := search.NewMemoryIndex()
+
+	s.mu.RLock()
+	for _, qu := range s.questions {
+		if !qu.DeletedAt.Valid {
+			idx.Put(search.Document{Kind: search.KindQuestion, ID: qu.ID, QuestionID: qu.ID, Text: qu.Text, CreatedAt: qu.CreatedAt})
+		}
+	}
+	for _, a := range s.answers {
+		if !a.DeletedAt.Valid {
+			idx.Put(search.Document{Kind: search.KindAnswer, ID: a.ID, QuestionID: a.QuestionID, Text: a.Text, CreatedAt: a.CreatedAt})
+		}
+	}
+	s.mu.RUnlock()
+
+	return idx.Search(ctx, q)
+}
@@ -0,0 +1,191 @@
+// Package memory keeps questions, answers, comments and votes in process
+// memory. It stands in for Postgres in demos and end-to-end tests and
+// mirrors the behaviour of the gorm repositories: soft deletes, cascades,
+// ordering and the one-answer-per-user limit.
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/comment"
+	"testTask/internal/question"
+	"testTask/internal/search"
+	"testTask/internal/vote"
+
+	"gorm.io/gorm"
+)
+
+// Store holds the rows shared by the question and answer storages, which
+// need each other's rows just like the Postgres tables reference each
+// other. One mutex guards everything, standing in for transactions.
+type Store struct {
+	mu sync.RWMutex
+
+	questions         map[uint]*question.Question
+	questionRevisions []question.Revision
+	statusChanges     []question.StatusChange
+	tags              map[uint]*question.Tag
+	// questionTags links questions to tag IDs.
+	questionTags map[uint]map[uint]bool
+
+	answers         map[uint]*answer.Answer
+	answerRevisions []answer.Revision
+
+	comments map[uint]*comment.Comment
+	votes    map[voteKey]int
+
+	seq struct {
+		question, questionRevision, statusChange, tag, answer, answerRevision, comment uint
+	}
+}
+
+// voteKey identifies the vote of a user on a question or an answer.
+type voteKey struct {
+	target vote.Target
+	id     uint
+	userID string
+}
+
+func NewStore() *Store {
+	return &Store{
+		questions:    make(map[uint]*question.Question),
+		tags:         make(map[uint]*question.Tag),
+		questionTags: make(map[uint]map[uint]bool),
+		answers:      make(map[uint]*answer.Answer),
+		comments:     make(map[uint]*comment.Comment),
+		votes:        make(map[voteKey]int),
+	}
+}
+
+// Questions returns a question.Storage over the store.
+func (s *Store) Questions() question.Storage {
+	return &questions{s}
+}
+
+// Answers returns an answer.Storage over the store.
+func (s *Store) Answers() answer.Storage {
+	return &answers{s}
+}
+
+// Comments returns a comment.Storage over the store.
+func (s *Store) Comments() comment.Storage {
+	return &comments{s}
+}
+
+// Votes returns a vote.Storage over the store.
+func (s *Store) Votes() vote.Storage {
+	return &votes{s}
+}
+
+// Index returns a search.Index over the live questions and answers of the
+// store.
+func (s *Store) Index() search.Index {
+	return &index{s}
+}
+
+// now matches the microsecond precision of Postgres timestamps so that
+// cursors round-trip the same way.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// liveAnswers returns the live answers of a question in no particular
+// order. The caller holds the lock.
+func (s *Store) liveAnswers(questionID uint) []*answer.Answer {
+	var list []*answer.Answer
+	for _, a := range s.answers {
+		if a.QuestionID == questionID && !a.DeletedAt.Valid {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// accepted reports whether an answer is the accepted one of its question.
+// The caller holds the lock.
+func (s *Store) accepted(a *answer.Answer) bool {
+	q, ok := s.questions[a.QuestionID]
+	return ok && q.AcceptedAnswerID != nil && *q.AcceptedAnswerID == a.ID
+}
+
+// answerCopy returns a copy of a together with its accepted flag. The
+// caller holds the lock.
+func (s *Store) answerCopy(a *answer.Answer) *answer.Answer {
+	c := *a
+	c.Accepted = s.accepted(a)
+	return &c
+}
+
+// withdrawAcceptance clears the accepted answer of any question pointing
+// at answerID, as ON DELETE SET NULL and the answer storage do. The caller
+// holds the write lock.
+func (s *Store) withdrawAcceptance(answerID uint) {
+	for _, q := range s.questions {
+		if q.AcceptedAnswerID != nil && *q.AcceptedAnswerID == answerID {
+			q.AcceptedAnswerID = nil
+		}
+	}
+}
+
+// hasExclusive reports whether the user holds a live exclusive answer to
+// the question other than exceptID, the partial unique index of Postgres.
+// The caller holds the lock.
+func (s *Store) hasExclusive(questionID uint, userID string, exceptID uint) bool {
+	for _, a := range s.liveAnswers(questionID) {
+		if a.ID != exceptID && a.Exclusive && a.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// purgeAnswer removes an answer and the rows hanging off it. The caller
+// holds the write lock.
+func (s *Store) purgeAnswer(id uint) {
+	delete(s.answers, id)
+	s.withdrawAcceptance(id)
+
+	s.answerRevisions = slices.DeleteFunc(s.answerRevisions, func(r answer.Revision) bool {
+		return r.AnswerID == id
+	})
+	s.purgeTarget(comment.TargetAnswer, vote.TargetAnswer, id)
+}
+
+// purgeTarget removes the comments and votes of a question or an answer,
+// as ON DELETE CASCADE does. The caller holds the write lock.
+func (s *Store) purgeTarget(c comment.Target, v vote.Target, id uint) {
+	for commentID, cm := range s.comments {
+		if target, targetID := cm.Target(); target == c && targetID == id {
+			delete(s.comments, commentID)
+		}
+	}
+	for k := range s.votes {
+		if k.target == v && k.id == id {
+			delete(s.votes, k)
+		}
+	}
+}
+
+// deleteComments soft-deletes the live comments on a question or an
+// answer with the deleted_at of their post. The caller holds the write
+// lock.
+func (s *Store) deleteComments(target comment.Target, id uint, deletedAt gorm.DeletedAt) {
+	for _, c := range s.comments {
+		if t, targetID := c.Target(); t == target && targetID == id && !c.DeletedAt.Valid {
+			c.DeletedAt = deletedAt
+		}
+	}
+}
+
+// restoreComments brings back the comments on a question or an answer
+// that were deleted together with it. The caller holds the write lock.
+func (s *Store) restoreComments(target comment.Target, id uint, deletedAt gorm.DeletedAt) {
+	for _, c := range s.comments {
+		if t, targetID := c.Target(); t == target && targetID == id && c.DeletedAt.Valid && c.DeletedAt.Time.Equal(deletedAt.Time) {
+			c.DeletedAt = gorm.DeletedAt{}
+		}
+	}
+}
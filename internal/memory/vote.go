@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"testTask/internal/vote"
+)
+
+type votes struct {
+	*Store
+}
+
+func (s *votes) Cast(ctx context.Context, target vote.Target, targetID uint, userID string, value int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var score *int64
+	switch target {
+	case vote.TargetQuestion:
+		if q, ok := s.questions[targetID]; ok && !q.DeletedAt.Valid {
+			score = &q.Score
+		}
+	case vote.TargetAnswer:
+		if a, ok := s.answers[targetID]; ok && !a.DeletedAt.Valid {
+			score = &a.Score
+		}
+	default:
+		return 0, fmt.Errorf("unknown vote target %q", target)
+	}
+	if score == nil {
+		return 0, vote.ErrNotFound
+	}
+
+	key := voteKey{target: target, id: targetID, userID: userID}
+	*score += int64(value - s.votes[key])
+	if value == 0 {
+		delete(s.votes, key)
+	} else {
+		s.votes[key] = value
+	}
+	return *score, nil
+}
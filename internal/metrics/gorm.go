@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"testTask/pkg/gormhook"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var (
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database statement latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database statements by operation and table. Lookups finding no row are not counted.",
+	}, []string{"operation", "table"})
+
+	dbMetrics = []prometheus.Collector{dbDuration, dbErrors}
+)
+
+const startKey = "metrics:start"
+
+// Plugin is a gorm plugin timing every statement. Install it with
+// db.Use(metrics.Plugin{}).
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "metrics"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	return gormhook.Register(db, "metrics", gormhook.Always(start), observe)
+}
+
+func start(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		dbDuration.WithLabelValues(operation, table).Observe(time.Since(v.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"testTask/internal/handlers"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "code"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	httpMetrics = []prometheus.Collector{httpRequests, httpDuration, httpInFlight}
+)
+
+// unmatchedRoute labels requests no pattern matched, so arbitrary paths do
+// not each get their own series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records every request under the mux pattern that serves it.
+func Middleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := handlers.Route(mux, r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			httpInFlight.Inc()
+			defer httpInFlight.Dec()
+
+			rec := handlers.NewResponseRecorder(w)
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+
+			httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.Status())).Inc()
+		})
+	}
+}
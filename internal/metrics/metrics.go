@@ -0,0 +1,86 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP traffic
+// through Middleware, database calls through the gorm Plugin, connection
+// pool stats and the domain counters services increment directly.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "qa"
+
+// Registry holds every metric of the service. It is separate from the
+// global default registry so tests and libraries cannot pollute it.
+var Registry = prometheus.NewRegistry()
+
+// Domain counters.
+var (
+	QuestionsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "questions_created_total",
+		Help:      "Questions created.",
+	})
+	QuestionStatusChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "question_status_changes_total",
+		Help:      "Question status changes by new status.",
+	}, []string{"status"})
+	AnswersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "answers_created_total",
+		Help:      "Answers created.",
+	})
+	AnswersRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "answers_rejected_total",
+		Help:      "Answers refused by a domain rule, by reason.",
+	}, []string{"reason"})
+	CommentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "comments_created_total",
+		Help:      "Comments created.",
+	})
+	VotesCast = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "votes_cast_total",
+		Help:      "Votes cast or retracted, by target kind and value.",
+	}, []string{"target", "value"})
+)
+
+// Reasons for AnswersRejected.
+const (
+	RejectDuplicate      = "duplicate"
+	RejectQuestionClosed = "question_closed"
+	RejectQuestionLocked = "question_locked"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		QuestionsCreated,
+		QuestionStatusChanges,
+		AnswersCreated,
+		AnswersRejected,
+		CommentsCreated,
+		VotesCast,
+	)
+	Registry.MustRegister(httpMetrics...)
+	Registry.MustRegister(dbMetrics...)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats exports the connection pool stats of db under the given
+// database name.
+func RegisterDBStats(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMiddleware_LabelsByPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h := Middleware(mux)(mux)
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/things/{id}", "418"))
+	for _, path := range []string{"/things/1", "/things/2"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere/42", nil))
+
+	assert.Equal(t, before+2, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/things/{id}", "418")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(httpInFlight))
+}
+
+func TestPlugin_ObservesStatements(t *testing.T) {
+	conn, err := sql.Open("pgx", "postgres://localhost/none")
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(Plugin{}))
+
+	type widget struct{ ID uint }
+	db.Find(&[]widget{})
+
+	assert.Equal(t, 1, testutil.CollectAndCount(dbDuration, "qa_db_query_duration_seconds"))
+}
+
+func TestHandler_ServesTextFormat(t *testing.T) {
+	QuestionsCreated.Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(rec.Body.String(), "qa_questions_created_total"))
+}
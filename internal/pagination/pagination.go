@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+// Cursor is a keyset position: the sort key it was issued for, the sort
+// value of the last returned row and that row's id as a tie breaker.
+type Cursor struct {
+	Key   string `json:"k"`
+	Value string `json:"v"`
+	ID    uint   `json:"i"`
+}
+
+// Page is the envelope returned by every paginated list endpoint.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses an opaque cursor and checks it was issued for key.
+// An empty string yields a nil cursor, meaning the first page.
+func Decode(s, key string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Key != key || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Limit applies the default page size and caps it at MaxLimit.
+func Limit(n int) (int, error) {
+	switch {
+	case n < 0:
+		return 0, ErrInvalidLimit
+	case n == 0:
+		return DefaultLimit, nil
+	case n > MaxLimit:
+		return MaxLimit, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package question
+
+import (
+	"context"
+	"time"
+
+	"testTask/pkg/logging"
+)
+
+// Archiver periodically archives questions that saw no activity for
+// longer than the configured period.
+type Archiver struct {
+	service  Service
+	after    time.Duration
+	interval time.Duration
+	logger   *logging.Logger
+}
+
+func NewArchiver(service Service, after, interval time.Duration, logger *logging.Logger) *Archiver {
+	return &Archiver{
+		service:  service,
+		after:    after,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run archives once immediately and then on every tick until ctx is done.
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.ArchiveOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Archiver) ArchiveOnce(ctx context.Context) {
+	before := time.Now().UTC().Add(-a.after)
+
+	n, err := a.service.ArchiveInactive(ctx, before)
+	if err != nil {
+		// The service has logged the cause already.
+		return
+	}
+	if n > 0 {
+		a.logger.Infof("archived %d questions inactive since %s", n, before.Format(time.RFC3339))
+	}
+}
@@ -4,12 +4,52 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"testTask/internal/answer"
+	answerdb "testTask/internal/answer/db"
+	"testTask/internal/comment"
 	"testTask/internal/question"
+	"testTask/internal/trash"
 	"testTask/pkg/logging"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const (
+	liveAnswers = "answers.question_id = questions.id AND answers.deleted_at IS NULL"
+
+	answerCountExpr  = "(SELECT COUNT(*) FROM answers WHERE " + liveAnswers + ")"
+	lastActivityExpr = "COALESCE((SELECT MAX(answers.created_at) FROM answers WHERE " + liveAnswers + "), questions.created_at)"
+	hasAnswersExpr   = "EXISTS (SELECT 1 FROM answers WHERE " + liveAnswers + ")"
+
+	taggedWith = "question_tags JOIN tags ON tags.id = question_tags.tag_id " +
+		"WHERE question_tags.question_id = questions.id AND tags.name IN ?"
+
+	// claimExclusive makes the oldest live answer of every user without an
+	// exclusive one exclusive, so that the unique index also covers answers
+	// given before the question switched to a single-answer policy.
+	claimExclusive = `
+		UPDATE answers SET exclusive = TRUE
+		WHERE id IN (
+			SELECT MIN(id) FROM answers
+			WHERE question_id = ? AND deleted_at IS NULL
+			GROUP BY user_id
+			HAVING SUM(CASE WHEN exclusive THEN 1 ELSE 0 END) = 0
+		)`
+)
+
+// questionTag is a row of the question_tags join table.
+type questionTag struct {
+	QuestionID uint
+	TagID      uint
+}
+
+func (questionTag) TableName() string {
+	return "question_tags"
+}
+
 type repository struct {
 	db     *gorm.DB
 	logger *logging.Logger
@@ -20,45 +60,537 @@ func NewStorage(db *gorm.DB, logger *logging.Logger) question.Storage {
 }
 
 func (r *repository) Create(ctx context.Context, q *question.Question) (*question.Question, error) {
-	if err := r.db.WithContext(ctx).Create(q).Error; err != nil {
-		r.logger.Errorf("failed to create question: %v", err)
+	names := question.TagNames(q.Tags)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags").Create(q).Error; err != nil {
+			return err
+		}
+
+		tags, err := setTags(tx, q.ID, names)
+		if err != nil {
+			return err
+		}
+		q.Tags = tags
+
+		return tx.Create(&question.Revision{
+			QuestionID: q.ID,
+			Text:       q.Text,
+			Tags:       question.TagNames(tags),
+			AuthorID:   q.AuthorID,
+		}).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to create question: %v", err)
 		return nil, fmt.Errorf("create question: %w", err)
 	}
+	q.LastActivityAt = q.CreatedAt
 	return q, nil
 }
 
 func (r *repository) FindOne(ctx context.Context, id uint) (*question.Question, error) {
 	var q question.Question
 
-	if err := r.db.WithContext(ctx).First(&q, id).Error; err != nil {
+	if err := r.withStats(ctx).First(&q, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		r.logger.Errorf("failed to find question id=%d: %v", id, err)
+		r.logger.Ctx(ctx).Errorf("failed to find question id=%d: %v", id, err)
 		return nil, fmt.Errorf("find question: %w", err)
 	}
 
 	return &q, nil
 }
 
-func (r *repository) FindAll(ctx context.Context) ([]question.Question, error) {
+func (r *repository) FindOneWithAnswers(ctx context.Context, id uint) (*question.Question, error) {
+	var q question.Question
+
+	if err := r.withStats(ctx).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("answers.*, " + answerdb.AcceptedExpr + " AS accepted").
+				Order("accepted DESC, answers.created_at ASC, answers.id ASC")
+		}).
+		First(&q, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find question with answers id=%d: %v", id, err)
+		return nil, fmt.Errorf("find question with answers: %w", err)
+	}
+
+	return &q, nil
+}
+
+func (r *repository) FindAll(ctx context.Context, filter question.ListFilter) ([]question.Question, error) {
 	var list []question.Question
 
-	if err := r.db.WithContext(ctx).
-		Order("created_at DESC").
+	query := r.withStats(ctx)
+
+	if filter.CreatedBefore != nil {
+		query = query.Where("questions.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("questions.created_at > ?", *filter.CreatedAfter)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.TagMode == question.TagModeAny {
+			query = query.Where("EXISTS (SELECT 1 FROM "+taggedWith+")", filter.Tags)
+		} else {
+			query = query.Where("(SELECT COUNT(*) FROM "+taggedWith+") = ?", filter.Tags, len(filter.Tags))
+		}
+	}
+	if filter.Solved != nil {
+		if *filter.Solved {
+			query = query.Where("questions.accepted_answer_id IS NOT NULL")
+		} else {
+			query = query.Where("questions.accepted_answer_id IS NULL")
+		}
+	}
+	if filter.HasAnswers != nil {
+		if *filter.HasAnswers {
+			query = query.Where(hasAnswersExpr)
+		} else {
+			query = query.Where("NOT " + hasAnswersExpr)
+		}
+	}
+
+	key, dir, cmp := "questions.created_at", "DESC", "<"
+	switch filter.Sort {
+	case question.SortAnswerCount:
+		key = answerCountExpr
+	case question.SortLastActivity:
+		key = lastActivityExpr
+	}
+	if filter.Order == question.OrderAsc {
+		dir, cmp = "ASC", ">"
+	}
+
+	if after := filter.After; after != nil {
+		var value any = after.Time
+		if filter.Sort == question.SortAnswerCount {
+			value = after.Count
+		}
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND questions.id %[2]s ?))", key, cmp),
+			value, value, after.ID,
+		)
+	}
+
+	if err := query.
+		Order(fmt.Sprintf("%s %s, questions.id %s", key, dir, dir)).
+		Limit(filter.Limit).
 		Find(&list).Error; err != nil {
-		r.logger.Errorf("failed to list questions: %v", err)
+		r.logger.Ctx(ctx).Errorf("failed to list questions: %v", err)
 		return nil, fmt.Errorf("list questions: %w", err)
 	}
 
 	return list, nil
 }
 
-func (r *repository) Delete(ctx context.Context, id uint) error {
+// withStats selects questions together with the derived columns used for
+// sorting and shown in responses.
+func (r *repository) withStats(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&question.Question{}).
+		Select(fmt.Sprintf("questions.*, %s AS answer_count, %s AS last_activity_at", answerCountExpr, lastActivityExpr)).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tags.name ASC")
+		}).
+		Preload("AcceptedAnswer", func(db *gorm.DB) *gorm.DB {
+			return db.Select("answers.*, " + answerdb.AcceptedExpr + " AS accepted")
+		})
+}
+
+func (r *repository) SetAcceptedAnswer(ctx context.Context, questionID uint, answerID *uint) error {
+	query := r.db.WithContext(ctx).Model(&question.Question{}).Where("id = ?", questionID)
+	if answerID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM answers WHERE answers.id = ? AND "+liveAnswers+")", *answerID)
+	}
+
+	res := query.UpdateColumn("accepted_answer_id", answerID)
+	if res.Error != nil {
+		r.logger.Ctx(ctx).Errorf("failed to set accepted answer of question id=%d: %v", questionID, res.Error)
+		return fmt.Errorf("set accepted answer: %w", res.Error)
+	}
+	if res.RowsAffected == 0 && answerID != nil {
+		return question.ErrAnswerNotOnQuestion
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, q *question.Question, editorID string, tags *[]string) (*question.Question, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored question.Question
+		if err := tx.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tags.name ASC")
+		}).First(&stored, q.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(q).Updates(map[string]any{
+			"text":          q.Text,
+			"answer_policy": q.AnswerPolicy,
+		}).Error; err != nil {
+			return err
+		}
+		if q.AnswerPolicy != stored.AnswerPolicy && q.AnswerPolicy.Exclusive() {
+			if err := tx.Exec(claimExclusive, q.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		before := question.TagNames(stored.Tags)
+		q.Tags = stored.Tags
+		if tags != nil && !slices.Equal(slices.Sorted(slices.Values(*tags)), before) {
+			var err error
+			if q.Tags, err = setTags(tx, q.ID, *tags); err != nil {
+				return err
+			}
+		}
+
+		after := question.TagNames(q.Tags)
+		if q.Text == stored.Text && slices.Equal(after, before) {
+			return nil
+		}
+		return tx.Create(&question.Revision{QuestionID: q.ID, Text: q.Text, Tags: after, AuthorID: editorID}).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to update question id=%d: %v", q.ID, err)
+		return nil, fmt.Errorf("update question: %w", err)
+	}
+	return q, nil
+}
+
+// setTags replaces the links of a question with the given tags, creating
+// the tags that do not exist yet. It must run inside a transaction.
+func setTags(tx *gorm.DB, questionID uint, names []string) ([]question.Tag, error) {
+	if err := tx.Where("question_id = ?", questionID).Delete(&questionTag{}).Error; err != nil {
+		return nil, err
+	}
+
+	tags := []question.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	rows := make([]question.Tag, 0, len(names))
+	for _, n := range names {
+		rows = append(rows, question.Tag{Name: n})
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("name IN ?", names).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	links := make([]questionTag, 0, len(tags))
+	for _, t := range tags {
+		links = append(links, questionTag{QuestionID: questionID, TagID: t.ID})
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *repository) FindTags(ctx context.Context) ([]question.TagUsage, error) {
+	var list []question.TagUsage
+
+	if err := r.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.name, COUNT(questions.id) AS count").
+		Joins("LEFT JOIN question_tags ON question_tags.tag_id = tags.id").
+		Joins("LEFT JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name ASC").
+		Scan(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list tags: %v", err)
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) FindTag(ctx context.Context, name string) (*question.Tag, error) {
+	var t question.Tag
+
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find tag %q: %v", name, err)
+		return nil, fmt.Errorf("find tag: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (r *repository) RenameTag(ctx context.Context, id uint, name string) error {
 	if err := r.db.WithContext(ctx).
-		Delete(&question.Question{}, id).Error; err != nil {
-		r.logger.Errorf("failed to delete question id=%d: %v", id, err)
+		Model(&question.Tag{}).
+		Where("id = ?", id).
+		Update("name", name).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to rename tag id=%d: %v", id, err)
+		return fmt.Errorf("rename tag: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) MergeTags(ctx context.Context, fromID, intoID uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO question_tags (question_id, tag_id) "+
+				"SELECT question_id, ? FROM question_tags WHERE tag_id = ? "+
+				"ON CONFLICT DO NOTHING",
+			intoID, fromID,
+		).Error; err != nil {
+			return err
+		}
+		// Links to the old tag go with it through ON DELETE CASCADE.
+		return tx.Delete(&question.Tag{}, fromID).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to merge tag id=%d into id=%d: %v", fromID, intoID, err)
+		return fmt.Errorf("merge tags: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) ChangeStatus(ctx context.Context, change *question.StatusChange) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Matching on the old status keeps two concurrent transitions from
+		// both succeeding.
+		res := tx.Model(&question.Question{}).
+			Where("id = ? AND status = ?", change.QuestionID, change.From).
+			Update("status", change.To)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return question.ErrInvalidTransition
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		if errors.Is(err, question.ErrInvalidTransition) {
+			return err
+		}
+		r.logger.Ctx(ctx).Errorf("failed to change status of question id=%d: %v", change.QuestionID, err)
+		return fmt.Errorf("change question status: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) FindStatusChanges(ctx context.Context, questionID uint) ([]question.StatusChange, error) {
+	var list []question.StatusChange
+
+	if err := r.db.WithContext(ctx).
+		Where("question_id = ?", questionID).
+		Order("id ASC").
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list status changes of question id=%d: %v", questionID, err)
+		return nil, fmt.Errorf("list question status changes: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) ArchiveInactive(ctx context.Context, from []question.Status, before time.Time) (int64, error) {
+	var archived int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The selected rows stay locked until the update, so their status
+		// is still the one recorded as From. SQLite has no row locks, but
+		// its transactions already keep other writers out.
+		var candidates []struct {
+			ID     uint
+			Status question.Status
+		}
+		if err := tx.Model(&question.Question{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, status").
+			Where("status IN ?", from).
+			Where(lastActivityExpr+" < ?", before).
+			Find(&candidates).Error; err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		ids := make([]uint, 0, len(candidates))
+		changes := make([]question.StatusChange, 0, len(candidates))
+		for _, c := range candidates {
+			ids = append(ids, c.ID)
+			changes = append(changes, question.StatusChange{
+				QuestionID: c.ID,
+				From:       c.Status,
+				To:         question.StatusArchived,
+				Reason:     question.ArchiveReason,
+				CreatedAt:  now,
+			})
+		}
+
+		if err := tx.Model(&question.Question{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{"status": question.StatusArchived, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		archived = int64(len(changes))
+		return tx.Create(&changes).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to archive inactive questions: %v", err)
+		return 0, fmt.Errorf("archive inactive questions: %w", err)
+	}
+	return archived, nil
+}
+
+func (r *repository) FindRevisions(ctx context.Context, questionID uint) ([]question.Revision, error) {
+	var list []question.Revision
+
+	if err := r.db.WithContext(ctx).
+		Where("question_id = ?", questionID).
+		Order("id ASC").
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list revisions of question id=%d: %v", questionID, err)
+		return nil, fmt.Errorf("list question revisions: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) FindRevision(ctx context.Context, questionID, revisionID uint) (*question.Revision, error) {
+	var rev question.Revision
+
+	if err := r.db.WithContext(ctx).
+		Where("question_id = ?", questionID).
+		First(&rev, revisionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find revision id=%d of question id=%d: %v", revisionID, questionID, err)
+		return nil, fmt.Errorf("find question revision: %w", err)
+	}
+
+	return &rev, nil
+}
+
+// commentsOfQuestion matches comments on a question and on its answers.
+const commentsOfQuestion = "question_id = ? OR answer_id IN (SELECT id FROM answers WHERE question_id = ?)"
+
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&question.Question{}).
+			Where("id = ?", id).
+			UpdateColumn("deleted_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		// Answers and comments share the question's deleted_at so Restore
+		// can tell them apart from those deleted on their own earlier.
+		if err := tx.Model(&answer.Answer{}).
+			Where("question_id = ?", id).
+			UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&comment.Comment{}).
+			Where(commentsOfQuestion, id, id).
+			UpdateColumn("deleted_at", now).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to delete question id=%d: %v", id, err)
 		return fmt.Errorf("delete question: %w", err)
 	}
 	return nil
 }
+
+func (r *repository) FindDeleted(ctx context.Context, filter trash.Filter) ([]question.Question, error) {
+	var list []question.Question
+
+	query := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+	if after := filter.After; after != nil {
+		query = query.Where("(deleted_at < ? OR (deleted_at = ? AND id < ?))", after.DeletedAt, after.DeletedAt, after.ID)
+	}
+
+	if err := query.
+		Order("deleted_at DESC, id DESC").
+		Limit(filter.Limit).
+		Find(&list).Error; err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to list deleted questions: %v", err)
+		return nil, fmt.Errorf("list deleted questions: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) FindOneDeleted(ctx context.Context, id uint) (*question.Question, error) {
+	var q question.Question
+
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&q, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Ctx(ctx).Errorf("failed to find deleted question id=%d: %v", id, err)
+		return nil, fmt.Errorf("find deleted question: %w", err)
+	}
+
+	return &q, nil
+}
+
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var q question.Question
+		if err := tx.Unscoped().First(&q, id).Error; err != nil {
+			return err
+		}
+		if !q.DeletedAt.Valid {
+			return nil
+		}
+
+		if err := tx.Unscoped().Model(&answer.Answer{}).
+			Where("question_id = ? AND deleted_at = ?", id, q.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&comment.Comment{}).
+			Where(commentsOfQuestion, id, id).
+			Where("deleted_at = ?", q.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&q).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		r.logger.Ctx(ctx).Errorf("failed to restore question id=%d: %v", id, err)
+		return fmt.Errorf("restore question: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	// Answers, comments and revisions go with the question through
+	// ON DELETE CASCADE.
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&question.Question{})
+	if res.Error != nil {
+		r.logger.Ctx(ctx).Errorf("failed to purge deleted questions: %v", res.Error)
+		return 0, fmt.Errorf("purge questions: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
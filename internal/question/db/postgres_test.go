@@ -0,0 +1,24 @@
+//go:build integration
+
+package db_test
+
+import (
+	"testing"
+
+	"testTask/internal/answer"
+	answerdb "testTask/internal/answer/db"
+	"testTask/internal/question"
+	questiondb "testTask/internal/question/db"
+	"testTask/internal/storagetest"
+	"testTask/pkg/logging"
+)
+
+func newStorages(t *testing.T) (question.Storage, answer.Storage) {
+	db := storagetest.Postgres(t)
+	logger := logging.GetLogger()
+	return questiondb.NewStorage(db, logger), answerdb.NewStorage(db, logger)
+}
+
+func TestQuestionStorage(t *testing.T) {
+	storagetest.TestQuestionStorage(t, newStorages)
+}
@@ -1,13 +1,38 @@
 package question
 
 import (
-	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"testTask/internal/handlers"
+	"testTask/internal/pagination"
+	"testTask/internal/trash"
+	"testTask/internal/validation"
 	"testTask/pkg/logging"
+	"time"
 )
 
+// ErrorMappings tells handlers.Error how to report question errors.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrNotFound, Status: http.StatusNotFound, Code: "question_not_found"},
+		{Err: ErrRevisionNotFound, Status: http.StatusNotFound, Code: "revision_not_found"},
+		{Err: ErrTagNotFound, Status: http.StatusNotFound, Code: "tag_not_found"},
+		{Err: ErrTagExists, Status: http.StatusConflict, Code: "tag_exists", Field: "name"},
+		{Err: ErrEmptyText, Status: http.StatusBadRequest, Code: "empty_text", Field: "text"},
+		{Err: ErrInvalidTag, Status: http.StatusBadRequest, Code: "invalid_tag", Field: "tags"},
+		{Err: ErrTooManyTags, Status: http.StatusBadRequest, Code: "too_many_tags", Field: "tags"},
+		{Err: ErrMergeIntoSelf, Status: http.StatusBadRequest, Code: "merge_into_self", Field: "into"},
+		{Err: ErrInvalidSort, Status: http.StatusBadRequest, Code: "invalid_sort", Field: "sort"},
+		{Err: ErrInvalidOrder, Status: http.StatusBadRequest, Code: "invalid_order", Field: "order"},
+		{Err: ErrInvalidRange, Status: http.StatusBadRequest, Code: "invalid_range", Field: "created_after"},
+		{Err: ErrInvalidTagMode, Status: http.StatusBadRequest, Code: "invalid_tag_mode", Field: "tag_mode"},
+		{Err: ErrInvalidTransition, Status: http.StatusConflict, Code: "invalid_transition"},
+		{Err: ErrAnswerNotOnQuestion, Status: http.StatusBadRequest, Code: "answer_not_on_question", Field: "answerId"},
+	}
+}
+
 type handler struct {
 	logger  *logging.Logger
 	service Service
@@ -24,37 +49,68 @@ func (h *handler) Register(router *http.ServeMux) {
 	router.HandleFunc("GET /questions/", h.GetAll)
 	router.HandleFunc("GET /questions/{id}", h.GetById)
 	router.HandleFunc("POST /questions/", h.Create)
+	router.HandleFunc("PUT /questions/{id}", h.Update)
+	router.HandleFunc("PATCH /questions/{id}", h.Update)
 	router.HandleFunc("DELETE /questions/{id}", h.Delete)
+	router.HandleFunc("POST /questions/{id}/restore", h.Restore)
+	router.HandleFunc("GET /admin/trash/questions", h.Trash)
+	router.HandleFunc("GET /questions/{id}/revisions", h.Revisions)
+	router.HandleFunc("GET /tags", h.Tags)
+	router.HandleFunc("PATCH /tags/{name}", h.RenameTag)
+	router.HandleFunc("POST /tags/{name}/merge", h.MergeTags)
+	router.HandleFunc("POST /questions/{id}/revisions/{revisionId}/rollback", h.Rollback)
+	router.HandleFunc("POST /questions/{id}/accept/{answerId}", h.Accept)
+	router.HandleFunc("DELETE /questions/{id}/accept", h.Unaccept)
+	router.HandleFunc("POST /questions/{id}/close", h.changeStatus(TransitionClose))
+	router.HandleFunc("POST /questions/{id}/reopen", h.changeStatus(TransitionReopen))
+	router.HandleFunc("POST /questions/{id}/lock", h.changeStatus(TransitionLock))
+	router.HandleFunc("POST /questions/{id}/unlock", h.changeStatus(TransitionUnlock))
+	router.HandleFunc("GET /questions/{id}/status-history", h.StatusHistory)
 }
 
 func (h *handler) GetAll(w http.ResponseWriter, r *http.Request) {
-	list, err := h.service.GetAll(r.Context())
+	params, err := parseListParams(r.URL.Query())
 	if err != nil {
-		h.logger.Errorf("list questions error: %v", err)
-		handlers.WriteError(w, http.StatusInternalServerError, "internal error")
+		handlers.Error(w, r, err)
 		return
 	}
 
-	handlers.WriteJSON(w, http.StatusOK, list)
+	page, err := h.service.GetAll(r.Context(), params)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, page)
 }
 
 func (h *handler) GetById(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	idUint, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := handlers.PathID(r, "id")
 	if err != nil {
-		handlers.WriteError(w, http.StatusBadRequest, "invalid id")
+		handlers.Error(w, r, err)
 		return
 	}
 
-	q, err := h.service.GetByID(r.Context(), uint(idUint))
-	if err != nil {
-		switch {
-		case errors.Is(err, ErrNotFound):
-			handlers.WriteError(w, http.StatusNotFound, err.Error())
+	includeAnswers := false
+	for _, inc := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(inc) {
+		case "":
+		case "answers":
+			includeAnswers = true
 		default:
-			h.logger.Errorf("get question error: %v", err)
-			handlers.WriteError(w, http.StatusInternalServerError, "internal error")
+			handlers.Error(w, r, validation.InvalidField("include", "only \"answers\" can be included"))
+			return
 		}
+	}
+
+	var q *Question
+	if includeAnswers {
+		q, err = h.service.GetWithAnswers(r.Context(), id)
+	} else {
+		q, err = h.service.GetByID(r.Context(), id)
+	}
+	if err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
@@ -64,44 +120,315 @@ func (h *handler) GetById(w http.ResponseWriter, r *http.Request) {
 func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateQuestionRequest
 	if err := handlers.ReadJSON(r, &req); err != nil {
-		h.logger.Errorf("failed to decode request: %v", err)
-		handlers.WriteError(w, http.StatusBadRequest, "invalid JSON body")
+		handlers.Error(w, r, err)
 		return
 	}
 	defer func() {
 		if err := r.Body.Close(); err != nil {
-			h.logger.Warnf("failed to close request body: %v", err)
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
 		}
 	}()
 
 	q, err := h.service.Create(r.Context(), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrEmptyText):
-			handlers.WriteError(w, http.StatusBadRequest, err.Error())
-		default:
-			h.logger.Errorf("create question error: %v", err)
-			handlers.WriteError(w, http.StatusInternalServerError, "internal error")
-		}
+		handlers.Error(w, r, err)
 		return
 	}
 
 	handlers.WriteJSON(w, http.StatusCreated, q)
 }
 
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	var req UpdateQuestionRequest
+	if err := handlers.ReadJSON(r, &req); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+		}
+	}()
+
+	q, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, q)
+}
+
+func (h *handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	q, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, q)
+}
+
+func (h *handler) Trash(w http.ResponseWriter, r *http.Request) {
+	params, err := trash.ParseParams(r.URL.Query())
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	page, err := h.service.Trash(r.Context(), params)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *handler) Revisions(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	list, err := h.service.Revisions(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	revisionID, err := handlers.PathID(r, "revisionId")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	q, err := h.service.Rollback(r.Context(), id, revisionID)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, q)
+}
+
+func (h *handler) Accept(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	answerID, err := handlers.PathID(r, "answerId")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	q, err := h.service.Accept(r.Context(), id, answerID)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, q)
+}
+
+func (h *handler) Unaccept(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	q, err := h.service.Unaccept(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, q)
+}
+
+// changeStatus serves one transition of the question state machine. The
+// body with a reason is optional for transitions that do not need one.
+func (h *handler) changeStatus(t Transition) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := handlers.PathID(r, "id")
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		var req ChangeStatusRequest
+		if r.ContentLength != 0 {
+			if err := handlers.ReadJSON(r, &req); err != nil {
+				handlers.Error(w, r, err)
+				return
+			}
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+			}
+		}()
+
+		q, err := h.service.ChangeStatus(r.Context(), id, t, &req)
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		handlers.WriteJSON(w, http.StatusOK, q)
+	}
+}
+
+func (h *handler) StatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := handlers.PathID(r, "id")
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	list, err := h.service.StatusHistory(r.Context(), id)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) Tags(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.Tags(r.Context())
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var req RenameTagRequest
+	if err := handlers.ReadJSON(r, &req); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+		}
+	}()
+
+	tag, err := h.service.RenameTag(r.Context(), r.PathValue("name"), &req)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, tag)
+}
+
+func (h *handler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var req MergeTagsRequest
+	if err := handlers.ReadJSON(r, &req); err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+		}
+	}()
+
+	tag, err := h.service.MergeTags(r.Context(), r.PathValue("name"), &req)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, tag)
+}
+
 func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	idUint, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := handlers.PathID(r, "id")
 	if err != nil {
-		handlers.WriteError(w, http.StatusBadRequest, "invalid id")
+		handlers.Error(w, r, err)
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), uint(idUint)); err != nil {
-		h.logger.Errorf("delete question error: %v", err)
-		handlers.WriteError(w, http.StatusInternalServerError, "internal error")
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		handlers.Error(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func parseListParams(q url.Values) (ListParams, error) {
+	p := ListParams{
+		Cursor:  q.Get("cursor"),
+		Sort:    SortField(q.Get("sort")),
+		Order:   Order(q.Get("order")),
+		Tags:    q["tag"],
+		TagMode: TagMode(q.Get("tag_mode")),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return p, pagination.ErrInvalidLimit
+		}
+		p.Limit = n
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"created_before": &p.CreatedBefore,
+		"created_after":  &p.CreatedAfter,
+	} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return p, validation.InvalidField(name, "must be an RFC 3339 timestamp")
+			}
+			*dst = &t
+		}
+	}
+
+	for name, dst := range map[string]**bool{
+		"has_answers": &p.HasAnswers,
+		"solved":      &p.Solved,
+	} {
+		if v := q.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return p, validation.InvalidField(name, "must be true or false")
+			}
+			*dst = &b
+		}
+	}
+
+	return p, nil
+}
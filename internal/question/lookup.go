@@ -0,0 +1,33 @@
+package question
+
+import (
+	"context"
+	"testTask/internal/answer"
+)
+
+type answerLookup struct {
+	storage Storage
+}
+
+// NewAnswerLookup lets the answer service check the question an answer
+// belongs to.
+func NewAnswerLookup(storage Storage) answer.QuestionLookup {
+	return &answerLookup{storage: storage}
+}
+
+func (l *answerLookup) LookupQuestion(ctx context.Context, id uint) (*answer.QuestionInfo, error) {
+	q, err := l.storage.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return nil, answer.ErrQuestionNotFound
+	}
+
+	return &answer.QuestionInfo{
+		ID:           q.ID,
+		AnswerPolicy: q.AnswerPolicy,
+		Closed:       q.Status == StatusClosed || q.Status == StatusArchived,
+		Locked:       q.Status == StatusLocked,
+	}, nil
+}
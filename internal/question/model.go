@@ -3,16 +3,121 @@ package question
 import (
 	"testTask/internal/answer"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Question struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Text      string    `gorm:"type:text; not null" json:"text"`
+	AuthorID  string    `gorm:"type:varchar(64);not null" json:"author_id"`
 	CreatedAt time.Time `gorm:"type:autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"`
+
+	// Score is the sum of votes, kept up to date by the vote storage.
+	Score int64 `gorm:"not null;default:0" json:"score"`
+
+	Status Status `gorm:"type:varchar(16);not null;default:open" json:"status"`
+
+	// AnswerPolicy says how many answers each user may give and whether
+	// they can be edited.
+	AnswerPolicy answer.Policy `gorm:"type:varchar(16);not null;default:single_editable" json:"answer_policy"`
+
+	// AcceptedAnswerID is set by the author through Service.Accept and
+	// cleared when the accepted answer is deleted.
+	AcceptedAnswerID *uint          `gorm:"index" json:"accepted_answer_id"`
+	AcceptedAnswer   *answer.Answer `gorm:"foreignKey:AcceptedAnswerID" json:"accepted_answer,omitempty"`
+
+	AnswerCount    int64     `gorm:"->" json:"answer_count"`
+	LastActivityAt time.Time `gorm:"->;serializer:timestamp" json:"last_activity_at"`
 
-	Answers []answer.Answer `gorm:"foreignKey:QuestionID" json:"answers,omitempty"`
+	Tags    []Tag           `gorm:"many2many:question_tags" json:"tags"`
+	Answers []answer.Answer `gorm:"foreignKey:QuestionID" json:"answers,omitzero"`
 }
 
 type CreateQuestionRequest struct {
-	Text string `json:"text" validate:"required"`
+	Text         string        `json:"text" validate:"required,max=10000"`
+	Tags         []string      `json:"tags" validate:"dive,max=32"`
+	AnswerPolicy answer.Policy `json:"answer_policy" validate:"omitempty,oneof=single single_editable multiple"`
+}
+
+// UpdateQuestionRequest changes the text, the tags, the answer policy or
+// any of them. Tags left out of the body stay as they are; an empty list
+// removes them all.
+type UpdateQuestionRequest struct {
+	Text         string        `json:"text" validate:"max=10000"`
+	Tags         []string      `json:"tags" validate:"dive,max=32"`
+	AnswerPolicy answer.Policy `json:"answer_policy" validate:"omitempty,oneof=single single_editable multiple"`
+}
+
+// Revision is one stored version of a question's text and tags. The first
+// revision is written on create, every edit or rollback appends another.
+type Revision struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	QuestionID uint   `gorm:"not null;index" json:"question_id"`
+	Text       string `gorm:"type:text;not null" json:"text"`
+	// Tags are the sorted tag names; nil for revisions written before tags
+	// were recorded.
+	Tags      []string  `gorm:"serializer:json" json:"tags"`
+	AuthorID  string    `gorm:"type:varchar(64);not null" json:"author_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Revision) TableName() string {
+	return "question_revisions"
+}
+
+type SortField string
+
+const (
+	SortCreatedAt    SortField = "created_at"
+	SortAnswerCount  SortField = "answers"
+	SortLastActivity SortField = "activity"
+)
+
+type Order string
+
+const (
+	OrderDesc Order = "desc"
+	OrderAsc  Order = "asc"
+)
+
+// ListParams is what a client asks for when listing questions.
+type ListParams struct {
+	Limit  int
+	Cursor string
+	Sort   SortField
+	Order  Order
+
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	HasAnswers    *bool
+	Solved        *bool
+	Tags          []string
+	TagMode       TagMode
+}
+
+// ListFilter is the resolved form of ListParams handed to Storage.
+type ListFilter struct {
+	Limit int
+	Sort  SortField
+	Order Order
+	After *Position
+
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	HasAnswers    *bool
+	Solved        *bool
+	Tags          []string
+	TagMode       TagMode
+}
+
+// Position is a decoded keyset cursor. Time is set for the created_at and
+// activity sorts, Count for the answers sort.
+type Position struct {
+	Time  time.Time
+	Count int64
+	ID    uint
 }
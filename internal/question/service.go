@@ -3,79 +3,775 @@ package question
 import (
 	"context"
 	"errors"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"testTask/internal/answer"
+	"testTask/internal/auth"
+	"testTask/internal/metrics"
+	"testTask/internal/pagination"
+	"testTask/internal/tracing"
+	"testTask/internal/trash"
+	"testTask/internal/validation"
 	"testTask/pkg/logging"
 )
 
 var (
-	ErrEmptyText = errors.New("question text is empty")
-	ErrNotFound  = errors.New("question not found")
+	ErrEmptyText    = errors.New("question text is empty")
+	ErrNotFound     = errors.New("question not found")
+	ErrInvalidSort  = errors.New("invalid sort field")
+	ErrInvalidOrder = errors.New("invalid sort order")
+	ErrInvalidRange = errors.New("created_after must be before created_before")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrAnswerNotOnQuestion = errors.New("answer does not belong to this question")
 )
 
 type Service interface {
 	Create(ctx context.Context, req *CreateQuestionRequest) (*Question, error)
 	GetByID(ctx context.Context, id uint) (*Question, error)
-	GetAll(ctx context.Context) ([]Question, error)
+	GetWithAnswers(ctx context.Context, id uint) (*Question, error)
+	GetAll(ctx context.Context, params ListParams) (*pagination.Page[Question], error)
+	Update(ctx context.Context, id uint, req *UpdateQuestionRequest) (*Question, error)
 	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (*Question, error)
+	Trash(ctx context.Context, params trash.Params) (*pagination.Page[Question], error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	Revisions(ctx context.Context, id uint) ([]Revision, error)
+	Rollback(ctx context.Context, id, revisionID uint) (*Question, error)
+	Tags(ctx context.Context) ([]TagUsage, error)
+	RenameTag(ctx context.Context, name string, req *RenameTagRequest) (*Tag, error)
+	MergeTags(ctx context.Context, name string, req *MergeTagsRequest) (*Tag, error)
+	// Accept marks one of the question's answers as accepted, replacing
+	// any earlier choice. Unaccept clears it.
+	Accept(ctx context.Context, id, answerID uint) (*Question, error)
+	Unaccept(ctx context.Context, id uint) (*Question, error)
+	// ChangeStatus applies a transition of the question state machine on
+	// behalf of the caller.
+	ChangeStatus(ctx context.Context, id uint, t Transition, req *ChangeStatusRequest) (*Question, error)
+	StatusHistory(ctx context.Context, id uint) ([]StatusChange, error)
+	// ArchiveInactive archives questions with no activity since before.
+	ArchiveInactive(ctx context.Context, before time.Time) (int64, error)
 }
 
 type service struct {
 	storage Storage
+	policy  auth.Policy
 	logger  *logging.Logger
 }
 
-func NewService(storage Storage, logger *logging.Logger) Service {
+func NewService(storage Storage, policy auth.Policy, logger *logging.Logger) Service {
 	return &service{
 		storage: storage,
+		policy:  policy,
 		logger:  logger,
 	}
 }
 
-func (s *service) Create(ctx context.Context, req *CreateQuestionRequest) (*Question, error) {
+func (s *service) Create(ctx context.Context, req *CreateQuestionRequest) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Create")
+	defer tracing.End(span, &err)
+
+	author, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
 	text := strings.TrimSpace(req.Text)
-	if text == "" {
-		return nil, ErrEmptyText
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	q := &Question{
-		Text: text,
+		Text:         text,
+		AuthorID:     author.UserID,
+		Status:       StatusOpen,
+		AnswerPolicy: req.AnswerPolicy,
+		Tags:         make([]Tag, 0, len(tags)),
+	}
+	if q.AnswerPolicy == "" {
+		q.AnswerPolicy = answer.DefaultPolicy
+	}
+	for _, name := range tags {
+		q.Tags = append(q.Tags, Tag{Name: name})
 	}
 
 	created, err := s.storage.Create(ctx, q)
 	if err != nil {
-		s.logger.Errorf("failed to create question: %v", err)
+		s.logger.Ctx(ctx).Errorf("failed to create question: %v", err)
 		return nil, err
 	}
+	metrics.QuestionsCreated.Inc()
 
 	return created, nil
 }
 
-func (s *service) GetByID(ctx context.Context, id uint) (*Question, error) {
+func (s *service) GetByID(ctx context.Context, id uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.GetByID")
+	defer tracing.End(span, &err)
+
 	q, err := s.storage.FindOne(ctx, id)
 	if err != nil {
-		s.logger.Errorf("failed to get question id=%d: %v", id, err)
+		s.logger.Ctx(ctx).Errorf("failed to get question id=%d: %v", id, err)
+		return nil, err
+	}
+	if q == nil {
+		return nil, ErrNotFound
+	}
+	return q, nil
+}
+
+func (s *service) GetWithAnswers(ctx context.Context, id uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.GetWithAnswers")
+	defer tracing.End(span, &err)
+
+	q, err := s.storage.FindOneWithAnswers(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get question with answers id=%d: %v", id, err)
 		return nil, err
 	}
 	if q == nil {
 		return nil, ErrNotFound
 	}
+	if q.Answers == nil {
+		q.Answers = []answer.Answer{}
+	}
 	return q, nil
 }
 
-func (s *service) GetAll(ctx context.Context) ([]Question, error) {
-	list, err := s.storage.FindAll(ctx)
+func (s *service) Accept(ctx context.Context, id, answerID uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Accept")
+	defer tracing.End(span, &err)
+
+	return s.setAccepted(ctx, id, &answerID)
+}
+
+func (s *service) Unaccept(ctx context.Context, id uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Unaccept")
+	defer tracing.End(span, &err)
+
+	return s.setAccepted(ctx, id, nil)
+}
+
+func (s *service) setAccepted(ctx context.Context, id uint, answerID *uint) (*Question, error) {
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionAccept, q.AuthorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.SetAcceptedAnswer(ctx, id, answerID); err != nil {
+		if !errors.Is(err, ErrAnswerNotOnQuestion) {
+			s.logger.Ctx(ctx).Errorf("failed to set accepted answer of question id=%d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) GetAll(ctx context.Context, params ListParams) (_ *pagination.Page[Question], err error) {
+	ctx, span := tracing.Start(ctx, "question.GetAll")
+	defer tracing.End(span, &err)
+
+	filter, err := resolveListParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ask for one extra row to learn whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+
+	list, err := s.storage.FindAll(ctx, filter)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list questions: %v", err)
+		return nil, err
+	}
+
+	page := &pagination.Page[Question]{Items: list}
+	if page.Items == nil {
+		page.Items = []Question{}
+	}
+	if len(list) > limit {
+		page.Items = list[:limit]
+		page.NextCursor = encodeCursor(filter, page.Items[limit-1])
+	}
+
+	return page, nil
+}
+
+func (s *service) Update(ctx context.Context, id uint, req *UpdateQuestionRequest) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Update")
+	defer tracing.End(span, &err)
+
+	editor, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	// Blank text is only allowed when the request changes something else.
+	text := strings.TrimSpace(req.Text)
+	if text == "" && req.Tags == nil && req.AnswerPolicy == "" {
+		return nil, ErrEmptyText
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeTags(req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	q, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(editor, auth.ActionEdit, q.AuthorID); err != nil {
+		return nil, err
+	}
+
+	// Text, tags and answer policy are written in one transaction together
+	// with the revision, so an edit is never half-applied.
+	changed := false
+	if text != "" && q.Text != text {
+		q.Text, changed = text, true
+	}
+	if req.AnswerPolicy != "" && q.AnswerPolicy != req.AnswerPolicy {
+		q.AnswerPolicy, changed = req.AnswerPolicy, true
+	}
+	var newTags *[]string
+	if req.Tags != nil && !slices.Equal(tags, TagNames(q.Tags)) {
+		newTags, changed = &tags, true
+	}
+	if !changed {
+		return q, nil
+	}
+
+	updated, err := s.storage.Update(ctx, q, editor.UserID, newTags)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to update question id=%d: %v", id, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *service) Revisions(ctx context.Context, id uint) (_ []Revision, err error) {
+	ctx, span := tracing.Start(ctx, "question.Revisions")
+	defer tracing.End(span, &err)
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.FindRevisions(ctx, id)
 	if err != nil {
-		s.logger.Errorf("failed to list questions: %v", err)
+		s.logger.Ctx(ctx).Errorf("failed to list revisions of question id=%d: %v", id, err)
 		return nil, err
 	}
+	if list == nil {
+		list = []Revision{}
+	}
 	return list, nil
 }
 
-func (s *service) Delete(ctx context.Context, id uint) error {
+func (s *service) Rollback(ctx context.Context, id, revisionID uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Rollback")
+	defer tracing.End(span, &err)
+
+	editor, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(editor, auth.ActionEdit, q.AuthorID); err != nil {
+		return nil, err
+	}
+
+	rev, err := s.storage.FindRevision(ctx, id, revisionID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get revision id=%d of question id=%d: %v", revisionID, id, err)
+		return nil, err
+	}
+	if rev == nil {
+		return nil, ErrRevisionNotFound
+	}
+	// Revisions from before tags were recorded restore the text only.
+	var tags *[]string
+	if rev.Tags != nil && !slices.Equal(rev.Tags, TagNames(q.Tags)) {
+		tags = &rev.Tags
+	}
+	if q.Text == rev.Text && tags == nil {
+		return q, nil
+	}
+
+	q.Text = rev.Text
+
+	updated, err := s.storage.Update(ctx, q, editor.UserID, tags)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to roll back question id=%d to revision id=%d: %v", id, revisionID, err)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "question.Delete")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	q, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionDelete, q.AuthorID); err != nil {
+		return err
+	}
+
 	if err := s.storage.Delete(ctx, id); err != nil {
-		s.logger.Errorf("failed to delete question id=%d: %v", id, err)
+		s.logger.Ctx(ctx).Errorf("failed to delete question id=%d: %v", id, err)
 		return err
 	}
 	return nil
 }
+
+func resolveListParams(p ListParams) (ListFilter, error) {
+	limit, err := pagination.Limit(p.Limit)
+	if err != nil {
+		return ListFilter{}, err
+	}
+
+	f := ListFilter{
+		Limit:         limit,
+		Sort:          p.Sort,
+		Order:         p.Order,
+		CreatedBefore: p.CreatedBefore,
+		CreatedAfter:  p.CreatedAfter,
+		HasAnswers:    p.HasAnswers,
+		Solved:        p.Solved,
+		TagMode:       p.TagMode,
+	}
+
+	switch f.Sort {
+	case "":
+		f.Sort = SortCreatedAt
+	case SortCreatedAt, SortAnswerCount, SortLastActivity:
+	default:
+		return ListFilter{}, ErrInvalidSort
+	}
+
+	switch f.Order {
+	case "":
+		f.Order = OrderDesc
+	case OrderDesc, OrderAsc:
+	default:
+		return ListFilter{}, ErrInvalidOrder
+	}
+
+	if len(p.Tags) > 0 {
+		// Filters are not capped at the per-question tag limit.
+		seen := make(map[string]bool, len(p.Tags))
+		for _, name := range p.Tags {
+			tag, err := NormalizeTag(name)
+			if err != nil {
+				return ListFilter{}, err
+			}
+			if !seen[tag] {
+				seen[tag] = true
+				f.Tags = append(f.Tags, tag)
+			}
+		}
+	}
+
+	switch f.TagMode {
+	case "":
+		f.TagMode = TagModeAll
+	case TagModeAll, TagModeAny:
+	default:
+		return ListFilter{}, ErrInvalidTagMode
+	}
+
+	if f.CreatedBefore != nil && f.CreatedAfter != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return ListFilter{}, ErrInvalidRange
+	}
+
+	c, err := pagination.Decode(p.Cursor, cursorKey(f))
+	if err != nil {
+		return ListFilter{}, err
+	}
+	if c != nil {
+		pos := &Position{ID: c.ID}
+		if f.Sort == SortAnswerCount {
+			pos.Count, err = strconv.ParseInt(c.Value, 10, 64)
+		} else {
+			pos.Time, err = time.Parse(time.RFC3339Nano, c.Value)
+		}
+		if err != nil {
+			return ListFilter{}, pagination.ErrInvalidCursor
+		}
+		f.After = pos
+	}
+
+	return f, nil
+}
+
+// cursorKey binds a cursor to the sort it was issued for, so a cursor from
+// one ordering cannot be replayed against another.
+func cursorKey(f ListFilter) string {
+	return string(f.Sort) + ":" + string(f.Order)
+}
+
+func encodeCursor(f ListFilter, last Question) string {
+	c := pagination.Cursor{Key: cursorKey(f), ID: last.ID}
+
+	switch f.Sort {
+	case SortAnswerCount:
+		c.Value = strconv.FormatInt(last.AnswerCount, 10)
+	case SortLastActivity:
+		c.Value = last.LastActivityAt.UTC().Format(time.RFC3339Nano)
+	default:
+		c.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return c.Encode()
+}
+
+func (s *service) Restore(ctx context.Context, id uint) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.Restore")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.storage.FindOneDeleted(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get deleted question id=%d: %v", id, err)
+		return nil, err
+	}
+	if q == nil {
+		return nil, ErrNotFound
+	}
+	if err := s.policy.Authorize(caller, auth.ActionRestore, q.AuthorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Restore(ctx, id); err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to restore question id=%d: %v", id, err)
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) Trash(ctx context.Context, params trash.Params) (_ *pagination.Page[Question], err error) {
+	ctx, span := tracing.Start(ctx, "question.Trash")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionViewTrash, ""); err != nil {
+		return nil, err
+	}
+
+	filter, err := trash.Resolve(params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.FindDeleted(ctx, filter)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list deleted questions: %v", err)
+		return nil, err
+	}
+
+	return trash.NewPage(list, filter, trashPosition), nil
+}
+
+func trashPosition(q Question) trash.Position {
+	return trash.Position{DeletedAt: q.DeletedAt.Time, ID: q.ID}
+}
+
+func (s *service) Purge(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "question.Purge")
+	defer tracing.End(span, &err)
+
+	n, err := s.storage.Purge(ctx, before)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to purge deleted questions: %v", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *service) Tags(ctx context.Context) (_ []TagUsage, err error) {
+	ctx, span := tracing.Start(ctx, "question.Tags")
+	defer tracing.End(span, &err)
+
+	list, err := s.storage.FindTags(ctx)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list tags: %v", err)
+		return nil, err
+	}
+	if list == nil {
+		list = []TagUsage{}
+	}
+	return list, nil
+}
+
+func (s *service) RenameTag(ctx context.Context, name string, req *RenameTagRequest) (_ *Tag, err error) {
+	ctx, span := tracing.Start(ctx, "question.RenameTag")
+	defer tracing.End(span, &err)
+
+	tag, err := s.authorizeTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	newName, err := NormalizeTag(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if newName == tag.Name {
+		return tag, nil
+	}
+
+	existing, err := s.storage.FindTag(ctx, newName)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get tag %q: %v", newName, err)
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrTagExists
+	}
+
+	if err := s.storage.RenameTag(ctx, tag.ID, newName); err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to rename tag %q to %q: %v", tag.Name, newName, err)
+		return nil, err
+	}
+
+	tag.Name = newName
+	return tag, nil
+}
+
+func (s *service) MergeTags(ctx context.Context, name string, req *MergeTagsRequest) (_ *Tag, err error) {
+	ctx, span := tracing.Start(ctx, "question.MergeTags")
+	defer tracing.End(span, &err)
+
+	from, err := s.authorizeTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	intoName, err := NormalizeTag(req.Into)
+	if err != nil {
+		return nil, err
+	}
+	if intoName == from.Name {
+		return nil, ErrMergeIntoSelf
+	}
+
+	into, err := s.storage.FindTag(ctx, intoName)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get tag %q: %v", intoName, err)
+		return nil, err
+	}
+	if into == nil {
+		return nil, ErrTagNotFound
+	}
+
+	if err := s.storage.MergeTags(ctx, from.ID, into.ID); err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to merge tag %q into %q: %v", from.Name, into.Name, err)
+		return nil, err
+	}
+
+	return into, nil
+}
+
+// authorizeTag checks that the caller may manage tags and loads the tag.
+func (s *service) authorizeTag(ctx context.Context, name string) (*Tag, error) {
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Authorize(caller, auth.ActionManageTag, ""); err != nil {
+		return nil, err
+	}
+
+	name, err = NormalizeTag(name)
+	if err != nil {
+		return nil, ErrTagNotFound
+	}
+
+	tag, err := s.storage.FindTag(ctx, name)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get tag %q: %v", name, err)
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+func (s *service) ChangeStatus(ctx context.Context, id uint, t Transition, req *ChangeStatusRequest) (_ *Question, err error) {
+	ctx, span := tracing.Start(ctx, "question.ChangeStatus")
+	defer tracing.End(span, &err)
+
+	caller, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+
+	tr, ok := transitions[t]
+	if !ok || tr.action == "" {
+		return nil, ErrInvalidTransition
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if tr.needsReason && reason == "" {
+		return nil, &validation.Error{Fields: []validation.FieldError{
+			{Field: "reason", Code: "required", Message: "is required"},
+		}}
+	}
+
+	q, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	action := tr.action
+	if tr.moderatedAction != "" && tr.allows(q.Status) {
+		moderated, err := s.statusSetByModerator(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if moderated {
+			action = tr.moderatedAction
+		}
+	}
+	if err := s.policy.Authorize(caller, action, q.AuthorID); err != nil {
+		return nil, err
+	}
+	if !tr.allows(q.Status) {
+		return nil, &TransitionError{Transition: t, Status: q.Status}
+	}
+
+	change := &StatusChange{
+		QuestionID: id,
+		From:       q.Status,
+		To:         tr.to,
+		ActorID:    caller.UserID,
+		ActorRole:  caller.Role,
+		Reason:     reason,
+	}
+	if err := s.storage.ChangeStatus(ctx, change); err != nil {
+		if !errors.Is(err, ErrInvalidTransition) {
+			s.logger.Ctx(ctx).Errorf("failed to %s question id=%d: %v", t, id, err)
+		}
+		return nil, err
+	}
+	metrics.QuestionStatusChanges.WithLabelValues(string(tr.to)).Inc()
+
+	q.Status = tr.to
+	return q, nil
+}
+
+// statusSetByModerator tells whether the current status of q was set by a
+// moderator or an admin. Archiving keeps the status it replaced in force, so
+// for an archived question the change before the archive decides. Statuses
+// set before roles were recorded count as not moderated.
+func (s *service) statusSetByModerator(ctx context.Context, q *Question) (bool, error) {
+	if q.Status == StatusOpen {
+		return false, nil
+	}
+	changes, err := s.storage.FindStatusChanges(ctx, q.ID)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to get status history of question id=%d: %v", q.ID, err)
+		return false, err
+	}
+	status := q.Status
+	for i := len(changes) - 1; i >= 0 && status != StatusOpen; i-- {
+		c := changes[i]
+		if c.To != status {
+			continue
+		}
+		if c.To != StatusArchived {
+			return c.ActorRole.AtLeast(auth.RoleModerator), nil
+		}
+		status = c.From
+	}
+	return false, nil
+}
+
+func (s *service) StatusHistory(ctx context.Context, id uint) (_ []StatusChange, err error) {
+	ctx, span := tracing.Start(ctx, "question.StatusHistory")
+	defer tracing.End(span, &err)
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	list, err := s.storage.FindStatusChanges(ctx, id)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to list status changes of question id=%d: %v", id, err)
+		return nil, err
+	}
+	if list == nil {
+		list = []StatusChange{}
+	}
+	return list, nil
+}
+
+func (s *service) ArchiveInactive(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "question.ArchiveInactive")
+	defer tracing.End(span, &err)
+
+	tr := transitions[TransitionArchive]
+
+	n, err := s.storage.ArchiveInactive(ctx, tr.from, before)
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to archive inactive questions: %v", err)
+		return 0, err
+	}
+	metrics.QuestionStatusChanges.WithLabelValues(string(tr.to)).Add(float64(n))
+	return n, nil
+}
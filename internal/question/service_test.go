@@ -2,14 +2,22 @@ package question
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	"testTask/internal/answer"
+	"testTask/internal/auth"
+	"testTask/internal/pagination"
+	"testTask/internal/trash"
+	"testTask/internal/validation"
 	"testTask/pkg/logging"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 type MockStorage struct {
@@ -32,19 +40,126 @@ func (m *MockStorage) FindOne(ctx context.Context, id uint) (*Question, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockStorage) FindAll(ctx context.Context) ([]Question, error) {
-	args := m.Called(ctx)
+func (m *MockStorage) FindOneWithAnswers(ctx context.Context, id uint) (*Question, error) {
+	args := m.Called(ctx, id)
+	if v := args.Get(0); v != nil {
+		return v.(*Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) FindAll(ctx context.Context, filter ListFilter) ([]Question, error) {
+	args := m.Called(ctx, filter)
 	if v := args.Get(0); v != nil {
 		return v.([]Question), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *MockStorage) Update(ctx context.Context, q *Question, editorID string, tags *[]string) (*Question, error) {
+	args := m.Called(ctx, q, editorID, tags)
+	if v := args.Get(0); v != nil {
+		return v.(*Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockStorage) Delete(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockStorage) FindDeleted(ctx context.Context, filter trash.Filter) ([]Question, error) {
+	args := m.Called(ctx, filter)
+	if v := args.Get(0); v != nil {
+		return v.([]Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) FindOneDeleted(ctx context.Context, id uint) (*Question, error) {
+	args := m.Called(ctx, id)
+	if v := args.Get(0); v != nil {
+		return v.(*Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Purge(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStorage) FindRevisions(ctx context.Context, questionID uint) ([]Revision, error) {
+	args := m.Called(ctx, questionID)
+	if v := args.Get(0); v != nil {
+		return v.([]Revision), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) FindRevision(ctx context.Context, questionID, revisionID uint) (*Revision, error) {
+	args := m.Called(ctx, questionID, revisionID)
+	if v := args.Get(0); v != nil {
+		return v.(*Revision), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) FindTags(ctx context.Context) ([]TagUsage, error) {
+	args := m.Called(ctx)
+	if v := args.Get(0); v != nil {
+		return v.([]TagUsage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) FindTag(ctx context.Context, name string) (*Tag, error) {
+	args := m.Called(ctx, name)
+	if v := args.Get(0); v != nil {
+		return v.(*Tag), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) RenameTag(ctx context.Context, id uint, name string) error {
+	args := m.Called(ctx, id, name)
+	return args.Error(0)
+}
+
+func (m *MockStorage) MergeTags(ctx context.Context, fromID, intoID uint) error {
+	args := m.Called(ctx, fromID, intoID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) SetAcceptedAnswer(ctx context.Context, questionID uint, answerID *uint) error {
+	args := m.Called(ctx, questionID, answerID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ChangeStatus(ctx context.Context, change *StatusChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockStorage) FindStatusChanges(ctx context.Context, questionID uint) ([]StatusChange, error) {
+	args := m.Called(ctx, questionID)
+	if v := args.Get(0); v != nil {
+		return v.([]StatusChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) ArchiveInactive(ctx context.Context, from []Status, before time.Time) (int64, error) {
+	args := m.Called(ctx, from, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func newTestService(t *testing.T) (*service, *MockStorage) {
 	t.Helper()
 
@@ -53,16 +168,42 @@ func newTestService(t *testing.T) (*service, *MockStorage) {
 
 	s := &service{
 		storage: storage,
+		policy:  auth.NewPolicy(),
 		logger:  logger,
 	}
 
 	return s, storage
 }
 
-func TestService_Create_EmptyText(t *testing.T) {
+func withUser(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleUser})
+}
+
+func withModerator(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleModerator})
+}
+
+func withAdmin(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleAdmin})
+}
+
+func TestService_Create_Unauthenticated(t *testing.T) {
 	svc, storage := newTestService(t)
 	ctx := context.Background()
 
+	q, err := svc.Create(ctx, &CreateQuestionRequest{Text: "test"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_EmptyText(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
 	storage.
 		On("Create", mock.Anything, mock.Anything).
 		Return(nil, nil).
@@ -70,8 +211,9 @@ func TestService_Create_EmptyText(t *testing.T) {
 
 	q, err := svc.Create(ctx, &CreateQuestionRequest{Text: "   "})
 
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, ErrEmptyText))
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, "text", ve.Fields[0].Field)
 	assert.Nil(t, q)
 
 	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
@@ -79,13 +221,14 @@ func TestService_Create_EmptyText(t *testing.T) {
 
 func TestService_Create_OK(t *testing.T) {
 	svc, storage := newTestService(t)
-	ctx := context.Background()
+	ctx := withUser("author")
 
 	req := &CreateQuestionRequest{Text: "  test  "}
 
 	storage.
 		On("Create", mock.Anything, mock.MatchedBy(func(q *Question) bool {
-			return q.Text == "test"
+			return q.Text == "test" && q.AuthorID == "author" &&
+				q.AnswerPolicy == answer.DefaultPolicy
 		})).
 		Return(&Question{ID: 1, Text: "test"}, nil)
 
@@ -134,6 +277,72 @@ func TestService_GetByID_StorageError(t *testing.T) {
 	storage.AssertExpectations(t)
 }
 
+func TestService_GetWithAnswers_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := context.Background()
+
+	expected := &Question{
+		ID:   7,
+		Text: "q",
+		Answers: []answer.Answer{
+			{ID: 1, QuestionID: 7, UserID: "u1", Text: "a1"},
+		},
+	}
+
+	storage.
+		On("FindOneWithAnswers", mock.Anything, uint(7)).
+		Return(expected, nil)
+
+	q, err := svc.GetWithAnswers(ctx, 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, q)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_GetWithAnswers_NoAnswers(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := context.Background()
+
+	storage.
+		On("FindOneWithAnswers", mock.Anything, uint(7)).
+		Return(&Question{ID: 7, Text: "q"}, nil)
+
+	q, err := svc.GetWithAnswers(ctx, 7)
+
+	require.NoError(t, err)
+	assert.NotNil(t, q.Answers)
+	assert.Empty(t, q.Answers)
+
+	body, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"answers":[]`, "an included empty list is kept")
+
+	body, err = json.Marshal(Question{ID: 7})
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), `"answers"`, "answers are left out unless included")
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_GetWithAnswers_NotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := context.Background()
+
+	storage.
+		On("FindOneWithAnswers", mock.Anything, uint(42)).
+		Return((*Question)(nil), nil)
+
+	q, err := svc.GetWithAnswers(ctx, 42)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, q)
+
+	storage.AssertExpectations(t)
+}
+
 func TestService_GetAll_OK(t *testing.T) {
 	svc, storage := newTestService(t)
 	ctx := context.Background()
@@ -144,22 +353,99 @@ func TestService_GetAll_OK(t *testing.T) {
 	}
 
 	storage.
-		On("FindAll", mock.Anything).
+		On("FindAll", mock.Anything, mock.MatchedBy(func(f ListFilter) bool {
+			return f.Limit == pagination.DefaultLimit+1 &&
+				f.Sort == SortCreatedAt &&
+				f.Order == OrderDesc &&
+				f.After == nil
+		})).
 		Return(expected, nil)
 
-	list, err := svc.GetAll(ctx)
+	page, err := svc.GetAll(ctx, ListParams{})
 
 	require.NoError(t, err)
-	require.Len(t, list, 2)
-	assert.Equal(t, expected, list)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, expected, page.Items)
+	assert.Empty(t, page.NextCursor)
 
 	storage.AssertExpectations(t)
 }
 
-func TestService_Delete_OK(t *testing.T) {
+func TestService_GetAll_NextCursor(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := context.Background()
+
+	created := time.Date(2025, 11, 14, 10, 0, 0, 0, time.UTC)
+	rows := []Question{
+		{ID: 3, Text: "q3", AnswerCount: 5},
+		{ID: 2, Text: "q2", AnswerCount: 4, CreatedAt: created},
+		{ID: 1, Text: "q1", AnswerCount: 1},
+	}
+
+	storage.
+		On("FindAll", mock.Anything, mock.MatchedBy(func(f ListFilter) bool {
+			return f.Limit == 3 && f.Sort == SortAnswerCount && f.After == nil
+		})).
+		Return(rows, nil).
+		Once()
+
+	page, err := svc.GetAll(ctx, ListParams{Limit: 2, Sort: SortAnswerCount})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	storage.
+		On("FindAll", mock.Anything, mock.MatchedBy(func(f ListFilter) bool {
+			return f.After != nil && f.After.ID == 2 && f.After.Count == 4
+		})).
+		Return(rows[2:], nil).
+		Once()
+
+	page, err = svc.GetAll(ctx, ListParams{Limit: 2, Sort: SortAnswerCount, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_GetAll_CursorFromOtherSort(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := context.Background()
+
+	cursor := pagination.Cursor{Key: "answers:desc", Value: "4", ID: 2}.Encode()
+
+	page, err := svc.GetAll(ctx, ListParams{Sort: SortCreatedAt, Cursor: cursor})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pagination.ErrInvalidCursor))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestService_GetAll_InvalidSort(t *testing.T) {
 	svc, storage := newTestService(t)
 	ctx := context.Background()
 
+	page, err := svc.GetAll(ctx, ListParams{Sort: "votes"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidSort))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("FindOne", mock.Anything, uint(10)).
+		Return(&Question{ID: 10, AuthorID: "author"}, nil)
 	storage.
 		On("Delete", mock.Anything, uint(10)).
 		Return(nil)
@@ -172,8 +458,11 @@ func TestService_Delete_OK(t *testing.T) {
 
 func TestService_Delete_Error(t *testing.T) {
 	svc, storage := newTestService(t)
-	ctx := context.Background()
+	ctx := withUser("author")
 
+	storage.
+		On("FindOne", mock.Anything, uint(10)).
+		Return(&Question{ID: 10, AuthorID: "author"}, nil)
 	delErr := errors.New("cannot delete")
 	storage.
 		On("Delete", mock.Anything, uint(10)).
@@ -186,3 +475,779 @@ func TestService_Delete_Error(t *testing.T) {
 
 	storage.AssertExpectations(t)
 }
+
+func TestService_Update_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "old", AuthorID: "editor"}, nil)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return q.ID == 3 && q.Text == "new"
+		}), "editor", (*[]string)(nil)).
+		Return(&Question{ID: 3, Text: "new"}, nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: " new "})
+
+	require.NoError(t, err)
+	assert.Equal(t, "new", q.Text)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_Unchanged(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "same", AuthorID: "editor"}, nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: "same"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "same", q.Text)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Update_EmptyText(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: "  "})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrEmptyText))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "FindOne", mock.Anything, mock.Anything)
+}
+
+func TestService_Update_NotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return((*Question)(nil), nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: "new"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Rollback_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "current"}, nil)
+	storage.
+		On("FindRevision", mock.Anything, uint(3), uint(1)).
+		Return(&Revision{ID: 1, QuestionID: 3, Text: "first"}, nil)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return q.Text == "first"
+		}), "moderator", (*[]string)(nil)).
+		Return(&Question{ID: 3, Text: "first"}, nil)
+
+	q, err := svc.Rollback(ctx, 3, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "first", q.Text)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Rollback_RevisionNotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "current"}, nil)
+	storage.
+		On("FindRevision", mock.Anything, uint(3), uint(9)).
+		Return((*Revision)(nil), nil)
+
+	q, err := svc.Rollback(ctx, 3, 9)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRevisionNotFound))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Restore_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("mod")
+
+	storage.
+		On("FindOneDeleted", mock.Anything, uint(4)).
+		Return(&Question{ID: 4, Text: "q", AuthorID: "author"}, nil)
+	storage.
+		On("Restore", mock.Anything, uint(4)).
+		Return(nil)
+	storage.
+		On("FindOne", mock.Anything, uint(4)).
+		Return(&Question{ID: 4, Text: "q"}, nil)
+
+	q, err := svc.Restore(ctx, 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), q.ID)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Restore_NotInTrash(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("FindOneDeleted", mock.Anything, uint(4)).
+		Return((*Question)(nil), nil)
+
+	q, err := svc.Restore(ctx, 4)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+func TestService_Trash_NextCursor(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	deleted := time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+	rows := []Question{
+		{ID: 9, DeletedAt: gorm.DeletedAt{Time: deleted.Add(time.Hour), Valid: true}},
+		{ID: 8, DeletedAt: gorm.DeletedAt{Time: deleted, Valid: true}},
+	}
+
+	storage.
+		On("FindDeleted", mock.Anything, trash.Filter{Limit: 2}).
+		Return(rows, nil)
+
+	page, err := svc.Trash(ctx, trash.Params{Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, uint(9), page.Items[0].ID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Delete_NotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	storage.
+		On("FindOne", mock.Anything, uint(10)).
+		Return((*Question)(nil), nil)
+
+	err := svc.Delete(ctx, 10)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_Forbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("someone-else")
+
+	storage.
+		On("FindOne", mock.Anything, uint(10)).
+		Return(&Question{ID: 10, AuthorID: "author"}, nil)
+
+	err := svc.Delete(ctx, 10)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+
+	var fe *auth.ForbiddenError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, auth.ActionDelete, fe.Action)
+
+	storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestService_Delete_ModeratorAnyQuestion(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	storage.
+		On("FindOne", mock.Anything, uint(10)).
+		Return(&Question{ID: 10, AuthorID: "author"}, nil)
+	storage.
+		On("Delete", mock.Anything, uint(10)).
+		Return(nil)
+
+	err := svc.Delete(ctx, 10)
+
+	require.NoError(t, err)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_Forbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("someone-else")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "old", AuthorID: "author"}, nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: "new"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	assert.Nil(t, q)
+
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Trash_Forbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	page, err := svc.Trash(ctx, trash.Params{})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	assert.Nil(t, page)
+
+	storage.AssertNotCalled(t, "FindDeleted", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_NormalizesTags(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("Create", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return len(q.Tags) == 2 && q.Tags[0].Name == "go" && q.Tags[1].Name == "postgres"
+		})).
+		Return(&Question{ID: 1, Text: "test"}, nil)
+
+	_, err := svc.Create(ctx, &CreateQuestionRequest{Text: "test", Tags: []string{"Postgres", "go", " GO "}})
+
+	require.NoError(t, err)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Create_TooManyTags(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	_, err := svc.Create(ctx, &CreateQuestionRequest{Text: "test", Tags: []string{"a", "b", "c", "d", "e", "f"}})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTooManyTags))
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Create_InvalidTag(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	_, err := svc.Create(ctx, &CreateQuestionRequest{Text: "test", Tags: []string{"no spaces"}})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidTag))
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Update_TagsOnly(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "same", AuthorID: "editor"}, nil)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return q.Text == "same"
+		}), "editor", &[]string{"go"}).
+		Return(&Question{ID: 3, Text: "same", Tags: []Tag{{ID: 1, Name: "go"}}}, nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Tags: []string{"Go"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "same", q.Text)
+	require.Len(t, q.Tags, 1)
+	assert.Equal(t, "go", q.Tags[0].Name)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_TextAndTagsTogether(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+	storageErr := errors.New("db down")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "old", AuthorID: "editor", Tags: []Tag{{ID: 1, Name: "go"}}}, nil)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return q.Text == "new"
+		}), "editor", &[]string{"sql"}).
+		Return(nil, storageErr).
+		Once()
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Text: "new", Tags: []string{"sql"}})
+
+	require.ErrorIs(t, err, storageErr)
+	assert.Nil(t, q)
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_SameTags(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "same", AuthorID: "editor", Tags: []Tag{{ID: 1, Name: "go"}}}, nil)
+
+	_, err := svc.Update(ctx, 3, &UpdateQuestionRequest{Tags: []string{"GO"}})
+
+	require.NoError(t, err)
+	storage.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Rollback_RestoresTags(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("moderator")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "same", Tags: []Tag{{ID: 2, Name: "sql"}}}, nil)
+	storage.
+		On("FindRevision", mock.Anything, uint(3), uint(1)).
+		Return(&Revision{ID: 1, QuestionID: 3, Text: "same", Tags: []string{"go"}}, nil)
+	storage.
+		On("Update", mock.Anything, mock.Anything, "moderator", &[]string{"go"}).
+		Return(&Question{ID: 3, Text: "same", Tags: []Tag{{ID: 1, Name: "go"}}}, nil)
+
+	q, err := svc.Rollback(ctx, 3, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, TagNames(q.Tags))
+	storage.AssertExpectations(t)
+}
+
+func TestService_Update_AnswerPolicyOnly(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("editor")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Text: "same", AuthorID: "editor", AnswerPolicy: answer.PolicySingleEditable}, nil)
+	storage.
+		On("Update", mock.Anything, mock.MatchedBy(func(q *Question) bool {
+			return q.Text == "same" && q.AnswerPolicy == answer.PolicyMultiple
+		}), "editor", (*[]string)(nil)).
+		Return(&Question{ID: 3, Text: "same", AnswerPolicy: answer.PolicyMultiple}, nil)
+
+	q, err := svc.Update(ctx, 3, &UpdateQuestionRequest{AnswerPolicy: answer.PolicyMultiple})
+
+	require.NoError(t, err)
+	assert.Equal(t, answer.PolicyMultiple, q.AnswerPolicy)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Create_InvalidAnswerPolicy(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	_, err := svc.Create(ctx, &CreateQuestionRequest{Text: "test", AnswerPolicy: "unlimited"})
+
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Fields, 1)
+	assert.Equal(t, "answer_policy", ve.Fields[0].Field)
+	assert.Equal(t, "oneof", ve.Fields[0].Code)
+	storage.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_GetAll_InvalidTagMode(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	_, err := svc.GetAll(context.Background(), ListParams{Tags: []string{"go"}, TagMode: "some"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidTagMode))
+	storage.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestService_RenameTag_Forbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("mod")
+
+	_, err := svc.RenameTag(ctx, "go", &RenameTagRequest{Name: "golang"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	storage.AssertNotCalled(t, "RenameTag", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_RenameTag_Exists(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withAdmin("root")
+
+	storage.On("FindTag", mock.Anything, "go").Return(&Tag{ID: 1, Name: "go"}, nil)
+	storage.On("FindTag", mock.Anything, "golang").Return(&Tag{ID: 2, Name: "golang"}, nil)
+
+	_, err := svc.RenameTag(ctx, "go", &RenameTagRequest{Name: "GoLang"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTagExists))
+	storage.AssertNotCalled(t, "RenameTag", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_RenameTag_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withAdmin("root")
+
+	storage.On("FindTag", mock.Anything, "go").Return(&Tag{ID: 1, Name: "go"}, nil)
+	storage.On("FindTag", mock.Anything, "golang").Return(nil, nil)
+	storage.On("RenameTag", mock.Anything, uint(1), "golang").Return(nil)
+
+	tag, err := svc.RenameTag(ctx, "go", &RenameTagRequest{Name: "golang"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "golang", tag.Name)
+	storage.AssertExpectations(t)
+}
+
+func TestService_MergeTags_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withAdmin("root")
+
+	storage.On("FindTag", mock.Anything, "golang").Return(&Tag{ID: 2, Name: "golang"}, nil)
+	storage.On("FindTag", mock.Anything, "go").Return(&Tag{ID: 1, Name: "go"}, nil)
+	storage.On("MergeTags", mock.Anything, uint(2), uint(1)).Return(nil)
+
+	tag, err := svc.MergeTags(ctx, "golang", &MergeTagsRequest{Into: "go"})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), tag.ID)
+	storage.AssertExpectations(t)
+}
+
+func TestService_MergeTags_IntoSelf(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withAdmin("root")
+
+	storage.On("FindTag", mock.Anything, "go").Return(&Tag{ID: 1, Name: "go"}, nil)
+
+	_, err := svc.MergeTags(ctx, "go", &MergeTagsRequest{Into: "Go"})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMergeIntoSelf))
+	storage.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Accept_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	answerID := uint(7)
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author"}, nil).
+		Once()
+	storage.On("SetAcceptedAnswer", mock.Anything, uint(3), &answerID).Return(nil)
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", AcceptedAnswerID: &answerID}, nil).
+		Once()
+
+	q, err := svc.Accept(ctx, 3, 7)
+
+	require.NoError(t, err)
+	require.NotNil(t, q.AcceptedAnswerID)
+	assert.Equal(t, uint(7), *q.AcceptedAnswerID)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Accept_ModeratorForbidden(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withModerator("mod")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author"}, nil)
+
+	_, err := svc.Accept(ctx, 3, 7)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	storage.AssertNotCalled(t, "SetAcceptedAnswer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Accept_AnswerOfOtherQuestion(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author"}, nil)
+	storage.
+		On("SetAcceptedAnswer", mock.Anything, uint(3), mock.Anything).
+		Return(ErrAnswerNotOnQuestion)
+
+	_, err := svc.Accept(ctx, 3, 99)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrAnswerNotOnQuestion))
+}
+
+func TestService_Unaccept_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author"}, nil)
+	storage.On("SetAcceptedAnswer", mock.Anything, uint(3), (*uint)(nil)).Return(nil)
+
+	q, err := svc.Unaccept(ctx, 3)
+
+	require.NoError(t, err)
+	assert.Nil(t, q.AcceptedAnswerID)
+
+	storage.AssertExpectations(t)
+}
+
+func TestAnswerLookup(t *testing.T) {
+	storage := &MockStorage{}
+	lookup := NewAnswerLookup(storage)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, Status: StatusLocked, AnswerPolicy: answer.PolicyMultiple}, nil)
+	storage.
+		On("FindOne", mock.Anything, uint(999)).
+		Return((*Question)(nil), nil)
+
+	info, err := lookup.LookupQuestion(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, &answer.QuestionInfo{ID: 3, AnswerPolicy: answer.PolicyMultiple, Locked: true}, info)
+
+	_, err = lookup.LookupQuestion(context.Background(), 999)
+	assert.True(t, errors.Is(err, answer.ErrQuestionNotFound))
+}
+
+func TestService_ChangeStatus_Close(t *testing.T) {
+	svc, storage := newTestService(t)
+	ctx := withUser("author")
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusOpen}, nil)
+	storage.
+		On("ChangeStatus", mock.Anything, &StatusChange{
+			QuestionID: 3,
+			From:       StatusOpen,
+			To:         StatusClosed,
+			ActorID:    "author",
+			ActorRole:  auth.RoleUser,
+			Reason:     "duplicate",
+		}).
+		Return(nil)
+
+	q, err := svc.ChangeStatus(ctx, 3, TransitionClose, &ChangeStatusRequest{Reason: " duplicate "})
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusClosed, q.Status)
+	storage.AssertExpectations(t)
+}
+
+func TestService_ChangeStatus_ReopenClosedByModerator(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusClosed}, nil)
+	storage.
+		On("FindStatusChanges", mock.Anything, uint(3)).
+		Return([]StatusChange{
+			{QuestionID: 3, From: StatusOpen, To: StatusClosed, ActorID: "author", ActorRole: auth.RoleUser},
+			{QuestionID: 3, From: StatusClosed, To: StatusOpen, ActorID: "author", ActorRole: auth.RoleUser},
+			{QuestionID: 3, From: StatusOpen, To: StatusClosed, ActorID: "mod", ActorRole: auth.RoleModerator},
+		}, nil)
+	storage.
+		On("ChangeStatus", mock.Anything, mock.Anything).
+		Return(nil)
+
+	_, err := svc.ChangeStatus(withUser("author"), 3, TransitionReopen, &ChangeStatusRequest{})
+
+	var fe *auth.ForbiddenError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, auth.ActionReopenClosed, fe.Action)
+	storage.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+
+	q, err := svc.ChangeStatus(withModerator("other-mod"), 3, TransitionReopen, &ChangeStatusRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, q.Status)
+}
+
+func TestService_ChangeStatus_ReopenArchivedAfterModeratorClose(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusArchived}, nil)
+	storage.
+		On("FindStatusChanges", mock.Anything, uint(3)).
+		Return([]StatusChange{
+			{QuestionID: 3, From: StatusOpen, To: StatusClosed, ActorID: "mod", ActorRole: auth.RoleModerator},
+			{QuestionID: 3, From: StatusClosed, To: StatusArchived},
+		}, nil)
+
+	_, err := svc.ChangeStatus(withUser("author"), 3, TransitionReopen, &ChangeStatusRequest{})
+
+	var fe *auth.ForbiddenError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, auth.ActionReopenClosed, fe.Action)
+	storage.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+}
+
+func TestService_ChangeStatus_ReopenArchivedOpenQuestion(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusArchived}, nil)
+	storage.
+		On("FindStatusChanges", mock.Anything, uint(3)).
+		Return([]StatusChange{
+			{QuestionID: 3, From: StatusOpen, To: StatusClosed, ActorID: "mod", ActorRole: auth.RoleModerator},
+			{QuestionID: 3, From: StatusClosed, To: StatusOpen, ActorID: "mod", ActorRole: auth.RoleModerator},
+			{QuestionID: 3, From: StatusOpen, To: StatusArchived},
+		}, nil)
+	storage.
+		On("ChangeStatus", mock.Anything, mock.Anything).
+		Return(nil)
+
+	q, err := svc.ChangeStatus(withUser("author"), 3, TransitionReopen, &ChangeStatusRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, q.Status)
+}
+
+func TestService_ChangeStatus_ReopenClosedByAuthor(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusClosed}, nil)
+	storage.
+		On("FindStatusChanges", mock.Anything, uint(3)).
+		Return([]StatusChange{
+			{QuestionID: 3, From: StatusOpen, To: StatusClosed, ActorID: "author", ActorRole: auth.RoleUser},
+		}, nil)
+	storage.
+		On("ChangeStatus", mock.Anything, mock.Anything).
+		Return(nil)
+
+	q, err := svc.ChangeStatus(withUser("author"), 3, TransitionReopen, &ChangeStatusRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, q.Status)
+}
+
+func TestService_ChangeStatus_ReasonRequired(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	_, err := svc.ChangeStatus(withUser("author"), 3, TransitionClose, &ChangeStatusRequest{})
+
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, "reason", ve.Fields[0].Field)
+	storage.AssertNotCalled(t, "FindOne", mock.Anything, mock.Anything)
+}
+
+func TestService_ChangeStatus_InvalidTransition(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		from Status
+		t    Transition
+	}{
+		{"close closed", StatusClosed, TransitionClose},
+		{"reopen open", StatusOpen, TransitionReopen},
+		{"close locked", StatusLocked, TransitionClose},
+		{"reopen locked", StatusLocked, TransitionReopen},
+		{"unlock open", StatusOpen, TransitionUnlock},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, storage := newTestService(t)
+
+			storage.
+				On("FindOne", mock.Anything, uint(3)).
+				Return(&Question{ID: 3, AuthorID: "author", Status: tc.from}, nil)
+
+			_, err := svc.ChangeStatus(withModerator("mod"), 3, tc.t, &ChangeStatusRequest{Reason: "why"})
+
+			var te *TransitionError
+			require.ErrorAs(t, err, &te)
+			assert.True(t, errors.Is(err, ErrInvalidTransition))
+			assert.Equal(t, tc.from, te.Status)
+			storage.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_ChangeStatus_LockNeedsModerator(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("FindOne", mock.Anything, uint(3)).
+		Return(&Question{ID: 3, AuthorID: "author", Status: StatusOpen}, nil)
+
+	_, err := svc.ChangeStatus(withUser("author"), 3, TransitionLock, &ChangeStatusRequest{Reason: "flame war"})
+
+	assert.True(t, errors.Is(err, auth.ErrForbidden))
+	storage.AssertNotCalled(t, "ChangeStatus", mock.Anything, mock.Anything)
+}
+
+func TestService_ChangeStatus_ArchiveIsNotManual(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	_, err := svc.ChangeStatus(withModerator("mod"), 3, TransitionArchive, &ChangeStatusRequest{})
+
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+}
+
+func TestService_ArchiveInactive(t *testing.T) {
+	svc, storage := newTestService(t)
+	before := time.Now()
+
+	storage.
+		On("ArchiveInactive", mock.Anything, []Status{StatusOpen, StatusClosed}, before).
+		Return(int64(2), nil)
+
+	n, err := svc.ArchiveInactive(context.Background(), before)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
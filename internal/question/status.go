@@ -0,0 +1,123 @@
+package question
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"testTask/internal/auth"
+)
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Status tells whether a question takes new answers.
+type Status string
+
+const (
+	StatusOpen Status = "open"
+	// StatusClosed questions take no new answers.
+	StatusClosed Status = "closed"
+	// StatusLocked questions take no new answers and their answers can no
+	// longer be edited.
+	StatusLocked Status = "locked"
+	// StatusArchived questions saw no activity for a long time and take no
+	// new answers until reopened.
+	StatusArchived Status = "archived"
+)
+
+// Transition names a move between statuses.
+type Transition string
+
+const (
+	TransitionClose   Transition = "close"
+	TransitionReopen  Transition = "reopen"
+	TransitionLock    Transition = "lock"
+	TransitionUnlock  Transition = "unlock"
+	TransitionArchive Transition = "archive"
+)
+
+type transition struct {
+	from []Status
+	to   Status
+	// action is what the caller must be authorized for. Transitions
+	// without one are only made by the service itself.
+	action auth.Action
+	// moderatedAction replaces action when the current status was set by
+	// a moderator, so that authors cannot undo moderation.
+	moderatedAction auth.Action
+	needsReason     bool
+}
+
+// transitions is the question state machine.
+var transitions = map[Transition]transition{
+	TransitionClose: {
+		from: []Status{StatusOpen}, to: StatusClosed,
+		action: auth.ActionEdit, needsReason: true,
+	},
+	TransitionReopen: {
+		from: []Status{StatusClosed, StatusArchived}, to: StatusOpen,
+		action: auth.ActionEdit, moderatedAction: auth.ActionReopenClosed,
+	},
+	TransitionLock: {
+		from: []Status{StatusOpen, StatusClosed, StatusArchived}, to: StatusLocked,
+		action: auth.ActionLock, needsReason: true,
+	},
+	TransitionUnlock: {
+		from: []Status{StatusLocked}, to: StatusOpen,
+		action: auth.ActionLock,
+	},
+	TransitionArchive: {
+		from: []Status{StatusOpen, StatusClosed}, to: StatusArchived,
+	},
+}
+
+func (t transition) allows(from Status) bool {
+	return slices.Contains(t.from, from)
+}
+
+// TransitionError tells that a transition does not apply to the current
+// status of a question. It matches ErrInvalidTransition with errors.Is.
+type TransitionError struct {
+	Transition Transition
+	Status     Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %s a question that is %s", e.Transition, e.Status)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// ProblemExtensions adds the refused transition and the current status to
+// the problem response.
+func (e *TransitionError) ProblemExtensions() map[string]any {
+	return map[string]any{"transition": e.Transition, "status": e.Status}
+}
+
+// StatusChange records who moved a question between statuses, when and
+// why. ActorID is empty for changes the service makes on its own, ActorRole
+// is the role the actor had at the time.
+type StatusChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	QuestionID uint      `gorm:"not null;index" json:"question_id"`
+	From       Status    `gorm:"column:from_status;type:varchar(16);not null" json:"from"`
+	To         Status    `gorm:"column:to_status;type:varchar(16);not null" json:"to"`
+	ActorID    string    `gorm:"type:varchar(64);not null" json:"actor_id,omitempty"`
+	ActorRole  auth.Role `gorm:"type:varchar(16);not null" json:"actor_role,omitempty"`
+	Reason     string    `gorm:"type:text;not null" json:"reason"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (StatusChange) TableName() string {
+	return "question_status_changes"
+}
+
+// ArchiveReason is recorded for questions archived for inactivity.
+const ArchiveReason = "inactive"
+
+type ChangeStatusRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -1,10 +1,52 @@
 package question
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"testTask/internal/trash"
+)
 
 type Storage interface {
+	// Create stores the question together with q.Tags, creating tags that
+	// do not exist yet. Only tag names need to be set.
 	Create(ctx context.Context, q *Question) (*Question, error)
 	FindOne(ctx context.Context, id uint) (*Question, error)
-	FindAll(ctx context.Context) ([]Question, error)
+	FindOneWithAnswers(ctx context.Context, id uint) (*Question, error)
+	FindAll(ctx context.Context, filter ListFilter) ([]Question, error)
+	// Update writes q.Text and q.AnswerPolicy and, unless tags is nil,
+	// replaces the tags of the question with the named ones, creating
+	// missing tags. A revision is recorded when the text or the tags
+	// changed. It all happens in one transaction.
+	Update(ctx context.Context, q *Question, editorID string, tags *[]string) (*Question, error)
+	// Delete soft-deletes the question together with its live answers.
 	Delete(ctx context.Context, id uint) error
+	FindDeleted(ctx context.Context, filter trash.Filter) ([]Question, error)
+	FindOneDeleted(ctx context.Context, id uint) (*Question, error)
+	// Restore brings back the question and the answers deleted with it.
+	Restore(ctx context.Context, id uint) error
+	// Purge hard-deletes questions that were soft-deleted before the given
+	// time and returns how many were removed.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	FindTags(ctx context.Context) ([]TagUsage, error)
+	FindTag(ctx context.Context, name string) (*Tag, error)
+	RenameTag(ctx context.Context, id uint, name string) error
+	// MergeTags moves every question tagged fromID over to intoID and
+	// removes fromID.
+	MergeTags(ctx context.Context, fromID, intoID uint) error
+	// SetAcceptedAnswer marks answerID as the accepted answer of the
+	// question, or clears it when answerID is nil. It returns
+	// ErrAnswerNotOnQuestion unless answerID is a live answer to it.
+	SetAcceptedAnswer(ctx context.Context, questionID uint, answerID *uint) error
+	// ChangeStatus moves a live question from change.From to change.To and
+	// records the change. It returns ErrInvalidTransition if the question
+	// is no longer in change.From.
+	ChangeStatus(ctx context.Context, change *StatusChange) error
+	FindStatusChanges(ctx context.Context, questionID uint) ([]StatusChange, error)
+	// ArchiveInactive archives live questions in one of the from statuses
+	// with no activity since before, records the changes and returns how
+	// many were archived.
+	ArchiveInactive(ctx context.Context, from []Status, before time.Time) (int64, error)
+	FindRevisions(ctx context.Context, questionID uint) ([]Revision, error)
+	FindRevision(ctx context.Context, questionID, revisionID uint) (*Revision, error)
 }
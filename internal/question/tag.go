@@ -0,0 +1,101 @@
+package question
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxTagLength       = 32
+	maxTagsPerQuestion = 5
+)
+
+var (
+	ErrInvalidTag     = errors.New("tag must be 1-32 characters of a-z, 0-9, '+', '#', '.' or '-'")
+	ErrTooManyTags    = errors.New("a question can have at most 5 tags")
+	ErrInvalidTagMode = errors.New("invalid tag mode")
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagExists      = errors.New("tag already exists")
+	ErrMergeIntoSelf  = errors.New("cannot merge a tag into itself")
+)
+
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(32);not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
+}
+
+// TagUsage is a tag with the number of live questions carrying it.
+type TagUsage struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type TagMode string
+
+const (
+	// TagModeAll keeps questions that carry every requested tag.
+	TagModeAll TagMode = "all"
+	// TagModeAny keeps questions that carry at least one requested tag.
+	TagModeAny TagMode = "any"
+)
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,max=32"`
+}
+
+type MergeTagsRequest struct {
+	Into string `json:"into" validate:"required,max=32"`
+}
+
+// NormalizeTag lower-cases and trims a tag name and checks its alphabet.
+func NormalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '+', r == '#', r == '.', r == '-':
+		default:
+			return "", ErrInvalidTag
+		}
+	}
+	return name, nil
+}
+
+// TagNames returns the names of tags in their order.
+func TagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// normalizeTags normalizes, de-duplicates and sorts tag names.
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+
+	for _, n := range names {
+		tag, err := NormalizeTag(n)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+
+	if len(out) > maxTagsPerQuestion {
+		return nil, ErrTooManyTags
+	}
+
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package question
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// The serializer is registered here, next to the model that names it, so
+// that every user of Question can scan it, not only question/db.
+func init() {
+	schema.RegisterSerializer("timestamp", timestampSerializer{})
+}
+
+// timestampLayouts are the text forms of timestamps in SQLite: the one
+// the driver writes and the one of CURRENT_TIMESTAMP.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05",
+}
+
+// timestampSerializer reads timestamps computed in SQL, such as
+// Question.LastActivityAt. SQLite returns those as text, since only table
+// columns have a declared type the driver can go by.
+type timestampSerializer struct{}
+
+func (timestampSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var t time.Time
+	switch v := dbValue.(type) {
+	case nil:
+	case time.Time:
+		t = v
+	case string:
+		var err error
+		if t, err = parseTimestamp(v); err != nil {
+			return err
+		}
+	case []byte:
+		var err error
+		if t, err = parseTimestamp(string(v)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported timestamp value %T", dbValue)
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(t))
+	return nil
+}
+
+func (timestampSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	return fieldValue, nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,33 @@
+package question
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+func TestQuestion_LastActivitySerializer(t *testing.T) {
+	s, err := schema.Parse(&Question{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	field := s.LookUpField("last_activity_at")
+	require.NotNil(t, field)
+	assert.IsType(t, timestampSerializer{}, field.Serializer, "registered without importing question/db")
+}
+
+func TestParseTimestamp(t *testing.T) {
+	got, err := parseTimestamp("2025-12-01 10:20:30.5+03:00")
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2025, 12, 1, 7, 20, 30, 5e8, time.UTC)))
+
+	got, err = parseTimestamp("2025-12-01 10:20:30")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 1, 10, 20, 30, 0, time.UTC), got)
+
+	_, err = parseTimestamp("yesterday")
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package db
+
+import (
+	"testTask/internal/search"
+	"testTask/pkg/logging"
+
+	"gorm.io/gorm"
+)
+
+// NewIndex returns the full-text index of the database behind db:
+// tsvector columns on Postgres, FTS5 tables on SQLite.
+func NewIndex(db *gorm.DB, logger *logging.Logger) search.Index {
+	if db.Dialector.Name() == "sqlite" {
+		return &sqliteIndex{db: db, logger: logger}
+	}
+	return &postgresIndex{db: db, logger: logger}
+}
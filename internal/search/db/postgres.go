@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testTask/internal/search"
+	"testTask/pkg/logging"
+
+	"gorm.io/gorm"
+)
+
+// Questions and answers carry a generated tsvector column (see the
+// add_search_vectors migration) backed by a GIN index. The 'simple' text
+// search configuration used below has to match the one used there.
+var headlineOptions = fmt.Sprintf(
+	"StartSel=%s, StopSel=%s, MaxWords=30, MinWords=10, MaxFragments=2",
+	search.MatchStart, search.MatchStop,
+)
+
+var kindQueries = map[search.Kind]string{
+	search.KindQuestion: `
+		SELECT 'question' AS kind, q.id, q.id AS question_id, q.text AS body, q.created_at,
+		       ts_rank(q.search_vector, query) AS rank
+		FROM questions q, websearch_to_tsquery('simple', @text) query
+		WHERE q.deleted_at IS NULL AND q.search_vector @@ query`,
+	search.KindAnswer: `
+		SELECT 'answer' AS kind, a.id, a.question_id, a.text AS body, a.created_at,
+		       ts_rank(a.search_vector, query) AS rank
+		FROM answers a, websearch_to_tsquery('simple', @text) query
+		WHERE a.deleted_at IS NULL AND a.search_vector @@ query`,
+}
+
+type postgresIndex struct {
+	db     *gorm.DB
+	logger *logging.Logger
+}
+
+func (i *postgresIndex) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	kinds := q.Kinds
+	if len(kinds) == 0 {
+		kinds = []search.Kind{search.KindQuestion, search.KindAnswer}
+	}
+
+	parts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		parts = append(parts, kindQueries[k])
+	}
+
+	// Headlines are expensive, so they are built only for the page that
+	// survives LIMIT/OFFSET.
+	sql := `
+		SELECT kind, id, question_id, created_at, rank,
+		       ts_headline('simple', body, websearch_to_tsquery('simple', @text), @options) AS snippet
+		FROM (` + strings.Join(parts, " UNION ALL ") + `
+			ORDER BY rank DESC, created_at DESC, kind, id
+			LIMIT @limit OFFSET @offset
+		) hits
+		ORDER BY rank DESC, created_at DESC, kind, id`
+
+	var hits []search.Hit
+	if err := i.db.WithContext(ctx).Raw(sql, map[string]any{
+		"text":    q.Text,
+		"options": headlineOptions,
+		"limit":   q.Limit,
+		"offset":  q.Offset,
+	}).Scan(&hits).Error; err != nil {
+		i.logger.Ctx(ctx).Errorf("failed to search %q: %v", q.Text, err)
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	for i := range hits {
+		hits[i].Snippet = search.HTMLSnippet(hits[i].Snippet)
+	}
+
+	return hits, nil
+}
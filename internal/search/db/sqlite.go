@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testTask/internal/search"
+	"testTask/pkg/logging"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// On SQLite questions and answers are indexed by the questions_fts and
+// answers_fts FTS5 tables (see migrations/sqlite). bm25 is lower for
+// better matches, so it is negated to rank like ts_rank.
+var sqliteKindQueries = map[search.Kind]string{
+	search.KindQuestion: `
+		SELECT 'question' AS kind, q.id, q.id AS question_id, q.created_at,
+		       -bm25(questions_fts) AS rank,
+		       snippet(questions_fts, 0, @start, @stop, ' ... ', 30) AS snippet
+		FROM questions_fts JOIN questions q ON q.id = questions_fts.rowid
+		WHERE questions_fts MATCH @match AND q.deleted_at IS NULL`,
+	search.KindAnswer: `
+		SELECT 'answer' AS kind, a.id, a.question_id, a.created_at,
+		       -bm25(answers_fts) AS rank,
+		       snippet(answers_fts, 0, @start, @stop, ' ... ', 30) AS snippet
+		FROM answers_fts JOIN answers a ON a.id = answers_fts.rowid
+		WHERE answers_fts MATCH @match AND a.deleted_at IS NULL`,
+}
+
+type sqliteIndex struct {
+	db     *gorm.DB
+	logger *logging.Logger
+}
+
+func (i *sqliteIndex) Search(ctx context.Context, q search.Query) ([]search.Hit, error) {
+	match := matchQuery(q.Text)
+	if match == "" {
+		return []search.Hit{}, nil
+	}
+
+	kinds := q.Kinds
+	if len(kinds) == 0 {
+		kinds = []search.Kind{search.KindQuestion, search.KindAnswer}
+	}
+
+	parts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		parts = append(parts, sqliteKindQueries[k])
+	}
+
+	sql := `
+		SELECT kind, id, question_id, created_at, rank, snippet
+		FROM (` + strings.Join(parts, " UNION ALL ") + `) hits
+		ORDER BY rank DESC, created_at DESC, kind, id
+		LIMIT @limit OFFSET @offset`
+
+	var hits []search.Hit
+	if err := i.db.WithContext(ctx).Raw(sql, map[string]any{
+		"match":  match,
+		"start":  search.MatchStart,
+		"stop":   search.MatchStop,
+		"limit":  q.Limit,
+		"offset": q.Offset,
+	}).Scan(&hits).Error; err != nil {
+		i.logger.Ctx(ctx).Errorf("failed to search %q: %v", q.Text, err)
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	for i := range hits {
+		hits[i].Snippet = search.HTMLSnippet(hits[i].Snippet)
+	}
+
+	return hits, nil
+}
+
+// matchQuery turns the web search syntax read by websearch_to_tsquery into
+// an FTS5 query: all words and "quoted phrases" must match, "or" between
+// two terms matches either of them and a leading "-" excludes a term.
+// Terms are always quoted, so FTS5 operators typed by users stay words.
+// It returns "" when nothing is left to match.
+func matchQuery(text string) string {
+	var (
+		groups  [][]string
+		exclude []string
+		or      bool
+	)
+
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		var chunk string
+		negated, phrase := false, false
+
+		if strings.HasPrefix(rest, "-") {
+			negated, rest = true, rest[1:]
+		}
+		if strings.HasPrefix(rest, `"`) {
+			phrase = true
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				chunk, rest = rest[1:], ""
+			} else {
+				chunk, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			chunk, rest = rest[:end], rest[end:]
+		}
+
+		if !negated && !phrase && strings.EqualFold(chunk, "or") {
+			or = len(groups) > 0
+			continue
+		}
+
+		words := strings.FieldsFunc(chunk, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		term := `"` + strings.Join(words, " ") + `"`
+
+		switch {
+		case negated:
+			exclude = append(exclude, term)
+		case or:
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+		default:
+			groups = append(groups, []string{term})
+		}
+		or = false
+	}
+
+	if len(groups) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if len(g) == 1 {
+			parts = append(parts, g[0])
+		} else {
+			parts = append(parts, "("+strings.Join(g, " OR ")+")")
+		}
+	}
+
+	query := strings.Join(parts, " AND ")
+	for _, term := range exclude {
+		query += " NOT " + term
+	}
+	return query
+}
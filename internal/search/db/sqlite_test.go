@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"testTask/internal/answer"
+	answerdb "testTask/internal/answer/db"
+	"testTask/internal/question"
+	questiondb "testTask/internal/question/db"
+	"testTask/internal/search"
+	"testTask/internal/storagetest"
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"golang", `"golang"`},
+		{"Go channels", `"Go" AND "channels"`},
+		{`"buffered channel" close`, `"buffered channel" AND "close"`},
+		{"go or rust", `("go" OR "rust")`},
+		{"or go", `"go"`},
+		{"channels -buffered", `"channels" NOT "buffered"`},
+		{"-buffered", ""},
+		{"NEAR(a b) AND c*", `"NEAR a" AND "b" AND "AND" AND "c"`},
+		{"вопрос про каналы", `"вопрос" AND "про" AND "каналы"`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchQuery(tt.text), tt.text)
+	}
+}
+
+func TestSQLiteIndex(t *testing.T) {
+	ctx := context.Background()
+	db := storagetest.SQLite(t)
+	logger := logging.GetLogger()
+	questions := questiondb.NewStorage(db, logger)
+	answers := answerdb.NewStorage(db, logger)
+	index := NewIndex(db, logger)
+
+	q, err := questions.Create(ctx, &question.Question{Text: "How do Go channels work?", Status: question.StatusOpen})
+	require.NoError(t, err)
+	other, err := questions.Create(ctx, &question.Question{Text: "Rust ownership", Status: question.StatusOpen})
+	require.NoError(t, err)
+	a, err := answers.Create(ctx, &answer.Answer{QuestionID: other.ID, UserID: "bob", Text: "Channels in Rust live in std::sync::mpsc"})
+	require.NoError(t, err)
+
+	hits, err := index.Search(ctx, search.Query{Text: "channels", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	kinds := map[search.Kind]uint{hits[0].Kind: hits[0].ID, hits[1].Kind: hits[1].ID}
+	assert.Equal(t, map[search.Kind]uint{search.KindQuestion: q.ID, search.KindAnswer: a.ID}, kinds)
+	for _, h := range hits {
+		assert.Contains(t, strings.ToLower(h.Snippet), search.HighlightStart+"channels"+search.HighlightStop)
+		assert.Positive(t, h.Rank)
+		assert.False(t, h.CreatedAt.IsZero())
+	}
+
+	hits, err = index.Search(ctx, search.Query{Text: "channels -rust", Kinds: []search.Kind{search.KindAnswer}, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	q.Text = "How do Go goroutines work?"
+	_, err = questions.Update(ctx, q, "alice", nil)
+	require.NoError(t, err)
+	require.NoError(t, answers.Delete(ctx, a.ID))
+
+	hits, err = index.Search(ctx, search.Query{Text: "channels", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, hits, "edited and deleted texts drop out")
+
+	hits, err = index.Search(ctx, search.Query{Text: "goroutines or ownership", Kinds: []search.Kind{search.KindQuestion}, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, hits, 2)
+
+	_, err = answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "eve", Text: "<b>Select</b> <script>alert(1)</script>"})
+	require.NoError(t, err)
+	hits, err = index.Search(ctx, search.Query{Text: "select", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "&lt;b&gt;<mark>Select</mark>&lt;/b&gt; &lt;script&gt;alert(1)&lt;/script&gt;", hits[0].Snippet)
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testTask/internal/handlers"
+	"testTask/internal/pagination"
+	"testTask/pkg/logging"
+)
+
+// ErrorMappings tells handlers.Error how to report malformed queries.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrEmptyQuery, Status: http.StatusBadRequest, Code: "empty_query", Field: "q"},
+		{Err: ErrQueryLength, Status: http.StatusBadRequest, Code: "query_too_long", Field: "q"},
+		{Err: ErrInvalidKind, Status: http.StatusBadRequest, Code: "invalid_type", Field: "type"},
+	}
+}
+
+type handler struct {
+	logger  *logging.Logger
+	service Service
+}
+
+func NewHandler(logger *logging.Logger, service Service) handlers.Handler {
+	return &handler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *handler) Register(router *http.ServeMux) {
+	router.HandleFunc("GET /search", h.Search)
+}
+
+func (h *handler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	params := SearchParams{
+		Text:   q.Get("q"),
+		Cursor: q.Get("cursor"),
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			handlers.Error(w, r, pagination.ErrInvalidLimit)
+			return
+		}
+		params.Limit = n
+	}
+	if v := q.Get("type"); v != "" {
+		for _, k := range strings.Split(v, ",") {
+			params.Kinds = append(params.Kinds, Kind(strings.TrimSpace(k)))
+		}
+	}
+
+	page, err := h.service.Search(r.Context(), params)
+	if err != nil {
+		handlers.Error(w, r, err)
+		return
+	}
+
+	handlers.WriteJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,9 @@
+package search
+
+import "context"
+
+// Index runs ranked full-text queries over questions and answers. Hits
+// are ordered by rank, best first, then by newest.
+type Index interface {
+	Search(ctx context.Context, q Query) ([]Hit, error)
+}
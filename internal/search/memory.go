@@ -0,0 +1,169 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Document is a piece of text known to a MemoryIndex.
+type Document struct {
+	Kind       Kind
+	ID         uint
+	QuestionID uint
+	Text       string
+	CreatedAt  time.Time
+}
+
+type docKey struct {
+	kind Kind
+	id   uint
+}
+
+// MemoryIndex is an Index kept in process memory. A document matches when
+// it contains every query term; rank is the share of the document's words
+// that are query terms. It is meant for tests and database-less runs.
+type MemoryIndex struct {
+	mu   sync.RWMutex
+	docs map[docKey]Document
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{docs: make(map[docKey]Document)}
+}
+
+// Put adds or replaces a document.
+func (m *MemoryIndex) Put(doc Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.docs[docKey{doc.Kind, doc.ID}] = doc
+}
+
+func (m *MemoryIndex) Remove(kind Kind, id uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.docs, docKey{kind, id})
+}
+
+func (m *MemoryIndex) Search(_ context.Context, q Query) ([]Hit, error) {
+	terms := tokenize(q.Text)
+	if len(terms) == 0 {
+		return []Hit{}, nil
+	}
+
+	wanted := make(map[Kind]bool, len(q.Kinds))
+	for _, k := range q.Kinds {
+		wanted[k] = true
+	}
+
+	m.mu.RLock()
+	var hits []Hit
+	for _, doc := range m.docs {
+		if len(wanted) > 0 && !wanted[doc.Kind] {
+			continue
+		}
+		rank, ok := score(doc.Text, terms)
+		if !ok {
+			continue
+		}
+		hits = append(hits, Hit{
+			Kind:       doc.Kind,
+			ID:         doc.ID,
+			QuestionID: doc.QuestionID,
+			Rank:       rank,
+			Snippet:    highlight(doc.Text, terms),
+			CreatedAt:  doc.CreatedAt,
+		})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+
+	if q.Offset >= len(hits) {
+		return []Hit{}, nil
+	}
+	hits = hits[q.Offset:]
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !isWordRune(r) })
+}
+
+func score(text string, terms []string) (float64, bool) {
+	words := tokenize(text)
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	counts := make(map[string]int, len(words))
+	for _, w := range words {
+		counts[w]++
+	}
+
+	matched := 0
+	for _, t := range terms {
+		if counts[t] == 0 {
+			return 0, false
+		}
+		matched += counts[t]
+	}
+
+	return float64(matched) / float64(len(words)), true
+}
+
+// highlight wraps every query term in text with the highlight markers,
+// keeping the original spelling and punctuation, and escapes the rest.
+func highlight(text string, terms []string) string {
+	set := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		set[t] = true
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if set[strings.ToLower(word)] {
+			b.WriteString(MatchStart)
+			b.WriteString(word)
+			b.WriteString(MatchStop)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return HTMLSnippet(b.String())
+}
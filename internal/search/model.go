@@ -0,0 +1,62 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindQuestion Kind = "question"
+	KindAnswer   Kind = "answer"
+)
+
+// Query is what an Index is asked for. Kinds restricts the result to the
+// given document kinds; empty means all.
+type Query struct {
+	Text   string
+	Kinds  []Kind
+	Limit  int
+	Offset int
+}
+
+// Hit is one ranked match. Snippet is an HTML-escaped excerpt of the text
+// with the matched words wrapped in <mark></mark>.
+type Hit struct {
+	Kind       Kind      `json:"type"`
+	ID         uint      `json:"id"`
+	QuestionID uint      `json:"question_id"`
+	Rank       float64   `json:"rank"`
+	Snippet    string    `json:"snippet"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SearchParams struct {
+	Text   string
+	Kinds  []Kind
+	Limit  int
+	Cursor string
+}
+
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Indexes mark matches with these private-use characters rather than with
+// markup, so that the text can be escaped around them by HTMLSnippet.
+const (
+	MatchStart = "\uE000"
+	MatchStop  = "\uE001"
+)
+
+var matchReplacer = strings.NewReplacer(MatchStart, HighlightStart, MatchStop, HighlightStop)
+
+// HTMLSnippet escapes text marked with MatchStart and MatchStop for HTML
+// and then turns the marks into <mark></mark>. Question and answer texts
+// are user input and must never reach a snippet unescaped.
+func HTMLSnippet(text string) string {
+	return matchReplacer.Replace(html.EscapeString(text))
+}
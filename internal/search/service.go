@@ -0,0 +1,112 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"testTask/internal/pagination"
+	"testTask/internal/tracing"
+	"testTask/pkg/logging"
+)
+
+var (
+	ErrEmptyQuery  = errors.New("search query is empty")
+	ErrQueryLength = errors.New("search query is too long")
+	ErrInvalidKind = errors.New("invalid search type")
+)
+
+const maxQueryLength = 256
+
+type Service interface {
+	Search(ctx context.Context, params SearchParams) (*pagination.Page[Hit], error)
+}
+
+type service struct {
+	index  Index
+	logger *logging.Logger
+}
+
+func NewService(index Index, logger *logging.Logger) Service {
+	return &service{
+		index:  index,
+		logger: logger,
+	}
+}
+
+func (s *service) Search(ctx context.Context, params SearchParams) (_ *pagination.Page[Hit], err error) {
+	ctx, span := tracing.Start(ctx, "search.Search")
+	defer tracing.End(span, &err)
+
+	text := strings.TrimSpace(params.Text)
+	if text == "" {
+		return nil, ErrEmptyQuery
+	}
+	if len(text) > maxQueryLength {
+		return nil, ErrQueryLength
+	}
+
+	for _, k := range params.Kinds {
+		if k != KindQuestion && k != KindAnswer {
+			return nil, ErrInvalidKind
+		}
+	}
+
+	limit, err := pagination.Limit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranked results have no stable keyset, so the cursor carries an
+	// offset bound to the query text it was issued for.
+	key := cursorKey(text, params.Kinds)
+	offset := 0
+	c, err := pagination.Decode(params.Cursor, key)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		if offset, err = strconv.Atoi(c.Value); err != nil || offset <= 0 {
+			return nil, pagination.ErrInvalidCursor
+		}
+	}
+
+	hits, err := s.index.Search(ctx, Query{
+		Text:   text,
+		Kinds:  params.Kinds,
+		Limit:  limit + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		s.logger.Ctx(ctx).Errorf("failed to search %q: %v", text, err)
+		return nil, err
+	}
+
+	page := &pagination.Page[Hit]{Items: hits}
+	if page.Items == nil {
+		page.Items = []Hit{}
+	}
+	if len(hits) > limit {
+		page.Items = hits[:limit]
+		page.NextCursor = pagination.Cursor{
+			Key:   key,
+			Value: strconv.Itoa(offset + limit),
+			ID:    page.Items[limit-1].ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
+
+func cursorKey(text string, kinds []Kind) string {
+	var b strings.Builder
+	b.WriteString("search:")
+	for _, k := range kinds {
+		b.WriteString(string(k))
+		b.WriteByte(',')
+	}
+	b.WriteByte(':')
+	b.WriteString(text)
+	return b.String()
+}
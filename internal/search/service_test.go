@@ -0,0 +1,134 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"testTask/internal/pagination"
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*service, *MemoryIndex) {
+	t.Helper()
+
+	index := NewMemoryIndex()
+	created := time.Date(2025, 11, 24, 10, 0, 0, 0, time.UTC)
+
+	index.Put(Document{Kind: KindQuestion, ID: 1, QuestionID: 1, Text: "How do I tune Postgres?", CreatedAt: created})
+	index.Put(Document{Kind: KindAnswer, ID: 1, QuestionID: 1, Text: "Postgres, postgres and more postgres.", CreatedAt: created.Add(time.Minute)})
+	index.Put(Document{Kind: KindAnswer, ID: 2, QuestionID: 1, Text: "Tune shared_buffers in Postgres first", CreatedAt: created.Add(2 * time.Minute)})
+	index.Put(Document{Kind: KindQuestion, ID: 2, QuestionID: 2, Text: "Goroutine leaks", CreatedAt: created})
+
+	svc := &service{
+		index:  index,
+		logger: logging.GetLogger(),
+	}
+
+	return svc, index
+}
+
+func TestService_Search_RankedWithSnippets(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.Search(ctx, SearchParams{Text: "postgres"})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, Hit{
+		Kind:       KindAnswer,
+		ID:         1,
+		QuestionID: 1,
+		Rank:       0.6,
+		Snippet:    "<mark>Postgres</mark>, <mark>postgres</mark> and more <mark>postgres</mark>.",
+		CreatedAt:  page.Items[0].CreatedAt,
+	}, page.Items[0])
+	for i := 1; i < len(page.Items); i++ {
+		assert.GreaterOrEqual(t, page.Items[i-1].Rank, page.Items[i].Rank)
+	}
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestService_Search_EscapesSnippets(t *testing.T) {
+	svc, index := newTestService(t)
+	index.Put(Document{Kind: KindAnswer, ID: 3, QuestionID: 2, Text: `<b>bold</b> <img src=x onerror="alert(1)"> & goroutines`})
+
+	page, err := svc.Search(context.Background(), SearchParams{Text: "bold"})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "&lt;b&gt;<mark>bold</mark>&lt;/b&gt; &lt;img src=x onerror=&#34;alert(1)&#34;&gt; &amp; goroutines", page.Items[0].Snippet)
+}
+
+func TestService_Search_AllTermsRequired(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.Search(ctx, SearchParams{Text: "tune postgres"})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	for _, h := range page.Items {
+		assert.Regexp(t, `<mark>[Tt]une</mark>`, h.Snippet)
+	}
+}
+
+func TestService_Search_KindFilter(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.Search(ctx, SearchParams{Text: "postgres", Kinds: []Kind{KindQuestion}})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, KindQuestion, page.Items[0].Kind)
+}
+
+func TestService_Search_Pages(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	var seen []Hit
+	params := SearchParams{Text: "postgres", Limit: 2}
+	for {
+		page, err := svc.Search(ctx, params)
+		require.NoError(t, err)
+		seen = append(seen, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+
+	assert.Len(t, seen, 3)
+}
+
+func TestService_Search_CursorBoundToQuery(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	page, err := svc.Search(ctx, SearchParams{Text: "postgres", Limit: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	_, err = svc.Search(ctx, SearchParams{Text: "goroutine", Cursor: page.NextCursor})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, pagination.ErrInvalidCursor))
+}
+
+func TestService_Search_Invalid(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Search(ctx, SearchParams{Text: "   "})
+	assert.True(t, errors.Is(err, ErrEmptyQuery))
+
+	_, err = svc.Search(ctx, SearchParams{Text: "postgres", Kinds: []Kind{"comment"}})
+	assert.True(t, errors.Is(err, ErrInvalidKind))
+}
@@ -0,0 +1,272 @@
+package storagetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/trash"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnswerStorage runs the answer.Storage suite against the storages
+// returned by newStorages.
+func TestAnswerStorage(t *testing.T, newStorages Factory) {
+	t.Run("NotFound", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		a, err := answers.FindOne(ctx, 42)
+		assert.NoError(t, err)
+		assert.Nil(t, a)
+
+		a, err = answers.FindOneDeleted(ctx, 42)
+		assert.NoError(t, err)
+		assert.Nil(t, a)
+
+		rev, err := answers.FindRevision(ctx, 42, 1)
+		assert.NoError(t, err)
+		assert.Nil(t, rev)
+
+		q := createQuestion(t, questions, "q")
+		a, err = answers.FindAccepted(ctx, q.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, a)
+
+		_, err = answers.Create(ctx, &answer.Answer{QuestionID: 42, UserID: "bob", Text: "orphan"})
+		assert.ErrorIs(t, err, answer.ErrQuestionNotFound)
+	})
+
+	t.Run("CreateAndUpdate", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		created := createAnswer(t, answers, q.ID, "bob")
+		require.NotZero(t, created.ID)
+
+		a, err := answers.FindOne(ctx, created.ID)
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		assert.Equal(t, "bob", a.UserID)
+		assert.False(t, a.Accepted)
+
+		a.Text = "edited"
+		_, err = answers.Update(ctx, a, "bob")
+		require.NoError(t, err)
+
+		a, err = answers.FindOne(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "edited", a.Text)
+
+		revisions, err := answers.FindRevisions(ctx, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, "edited", revisions[1].Text)
+
+		rev, err := answers.FindRevision(ctx, created.ID, revisions[0].ID)
+		require.NoError(t, err)
+		require.NotNil(t, rev)
+		assert.Equal(t, "answer by bob", rev.Text)
+	})
+
+	t.Run("FindByQuestionOrder", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		other := createQuestion(t, questions, "other")
+		first := createAnswer(t, answers, q.ID, "bob")
+		second := createAnswer(t, answers, q.ID, "carol")
+		third := createAnswer(t, answers, q.ID, "dave")
+		createAnswer(t, answers, other.ID, "bob")
+		deleted := createAnswer(t, answers, q.ID, "erin")
+		require.NoError(t, answers.Delete(ctx, deleted.ID))
+
+		list, err := answers.FindByQuestion(ctx, q.ID, answer.ListFilter{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID, second.ID}, answerIDs(list), "oldest first")
+
+		list, err = answers.FindByQuestion(ctx, q.ID, answer.ListFilter{
+			Limit: 10,
+			After: &answer.Position{Time: list[1].CreatedAt, ID: list[1].ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{third.ID}, answerIDs(list), "keyset cursor")
+
+		list, err = answers.FindByQuestion(ctx, q.ID, answer.ListFilter{Limit: 10, ExcludeID: second.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID, third.ID}, answerIDs(list))
+
+		list, err = answers.FindByQuestion(ctx, q.ID, answer.ListFilter{Limit: 10, Sort: answer.SortScore})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID, second.ID, third.ID}, answerIDs(list), "equal scores by ID")
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		first := createAnswer(t, answers, q.ID, "bob")
+
+		_, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "again", Exclusive: true})
+		assert.ErrorIs(t, err, answer.ErrAlreadyAnswered)
+
+		_, err = answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "extra"})
+		assert.NoError(t, err, "answers outside the limit")
+
+		require.NoError(t, answers.Delete(ctx, first.ID))
+		second := createAnswer(t, answers, q.ID, "bob")
+		assert.ErrorIs(t, answers.Restore(ctx, first.ID), answer.ErrAlreadyAnswered)
+
+		require.NoError(t, answers.Delete(ctx, second.ID))
+		assert.NoError(t, answers.Restore(ctx, first.ID))
+	})
+
+	t.Run("HasAnswered", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		a := createAnswer(t, answers, q.ID, "bob")
+		require.NoError(t, answers.Delete(ctx, a.ID))
+
+		answered, err := answers.HasAnswered(ctx, q.ID, "bob")
+		require.NoError(t, err)
+		assert.True(t, answered, "answers in the trash count")
+
+		answered, err = answers.HasAnswered(ctx, q.ID, "carol")
+		require.NoError(t, err)
+		assert.False(t, answered)
+	})
+
+	t.Run("SwitchToSinglePolicy", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		q.AnswerPolicy = answer.PolicyMultiple
+		_, err := questions.Update(ctx, q, "alice", nil)
+		require.NoError(t, err)
+
+		for _, user := range []string{"bob", "bob", "carol"} {
+			_, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: user, Text: "by " + user})
+			require.NoError(t, err)
+		}
+
+		q.AnswerPolicy = answer.PolicySingleEditable
+		_, err = questions.Update(ctx, q, "alice", nil)
+		require.NoError(t, err)
+
+		for _, user := range []string{"bob", "carol"} {
+			_, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: user, Text: "exclusive", Exclusive: true})
+			assert.ErrorIs(t, err, answer.ErrAlreadyAnswered, user)
+		}
+		createAnswer(t, answers, q.ID, "dave")
+
+		list, err := answers.FindByQuestion(ctx, q.ID, answer.ListFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, list, 4, "earlier answers are kept")
+
+		q.AnswerPolicy = answer.PolicyMultiple
+		_, err = questions.Update(ctx, q, "alice", nil)
+		require.NoError(t, err)
+		q.AnswerPolicy = answer.PolicySingle
+		_, err = questions.Update(ctx, q, "alice", nil)
+		require.NoError(t, err, "users who already hold an exclusive answer keep it")
+	})
+
+	t.Run("ConcurrentInserts", func(t *testing.T) {
+		questions, answers := newStorages(t)
+		q := createQuestion(t, questions, "q")
+
+		const workers = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for range workers {
+			wg.Go(func() {
+				_, err := answers.Create(ctx, &answer.Answer{QuestionID: q.ID, UserID: "bob", Text: "race", Exclusive: true})
+				errs <- err
+			})
+		}
+		wg.Wait()
+		close(errs)
+
+		created := 0
+		for err := range errs {
+			if err == nil {
+				created++
+				continue
+			}
+			assert.ErrorIs(t, err, answer.ErrAlreadyAnswered)
+		}
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("DeleteWithdrawsAcceptance", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		a := createAnswer(t, answers, q.ID, "bob")
+		require.NoError(t, questions.SetAcceptedAnswer(ctx, q.ID, &a.ID))
+
+		accepted, err := answers.FindAccepted(ctx, q.ID)
+		require.NoError(t, err)
+		require.NotNil(t, accepted)
+		assert.True(t, accepted.Accepted)
+
+		require.NoError(t, answers.Delete(ctx, a.ID))
+
+		got, err := questions.FindOne(ctx, q.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.AcceptedAnswerID)
+
+		require.NoError(t, answers.Restore(ctx, a.ID))
+		got, err = questions.FindOne(ctx, q.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.AcceptedAnswerID, "restoring does not accept again")
+	})
+
+	t.Run("RestoreNeedsLiveQuestion", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		a := createAnswer(t, answers, q.ID, "bob")
+		require.NoError(t, answers.Delete(ctx, a.ID))
+		require.NoError(t, questions.Delete(ctx, q.ID))
+
+		assert.ErrorIs(t, answers.Restore(ctx, a.ID), answer.ErrQuestionDeleted)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		first := createAnswer(t, answers, q.ID, "bob")
+		second := createAnswer(t, answers, q.ID, "carol")
+		createAnswer(t, answers, q.ID, "dave")
+		require.NoError(t, answers.Delete(ctx, first.ID))
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, answers.Delete(ctx, second.ID))
+
+		list, err := answers.FindDeleted(ctx, trash.Filter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{second.ID, first.ID}, answerIDs(list), "recently deleted first")
+
+		list, err = answers.FindDeleted(ctx, trash.Filter{
+			Limit: 10,
+			After: &trash.Position{DeletedAt: list[0].DeletedAt.Time, ID: list[0].ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID}, answerIDs(list))
+
+		n, err := answers.Purge(ctx, list[0].DeletedAt.Time.Add(time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		gone, err := answers.FindOneDeleted(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Nil(t, gone)
+		revisions, err := answers.FindRevisions(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+	})
+}
@@ -0,0 +1,74 @@
+//go:build integration
+
+package storagetest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"testing"
+
+	"testTask/migrations"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// DatabaseURLEnv names the variable pointing Postgres to a throwaway
+// database, e.g. the one started by `make test-integration`.
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// Postgres returns a gorm handle on a fresh, fully migrated schema of the
+// database in TEST_DATABASE_URL. The schema is dropped when the test ends.
+func Postgres(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	base := os.Getenv(DatabaseURLEnv)
+	if base == "" {
+		t.Skipf("%s is not set", DatabaseURLEnv)
+	}
+
+	admin, err := sql.Open("pgx", base)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = admin.Close() })
+
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	u, err := url.Parse(base)
+	require.NoError(t, err)
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	conn, err := sql.Open("pgx", u.String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	provider, err := migrations.NewProvider(goose.DialectPostgres, conn)
+	require.NoError(t, err)
+	_, err = provider.Up(context.Background())
+	require.NoError(t, err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	require.NoError(t, err)
+	return db
+}
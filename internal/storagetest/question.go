@@ -0,0 +1,338 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"testTask/internal/answer"
+	"testTask/internal/auth"
+	"testTask/internal/question"
+	"testTask/internal/trash"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuestionStorage runs the question.Storage suite against the storages
+// returned by newStorages.
+func TestQuestionStorage(t *testing.T, newStorages Factory) {
+	t.Run("NotFound", func(t *testing.T) {
+		questions, _ := newStorages(t)
+
+		q, err := questions.FindOne(ctx, 42)
+		assert.NoError(t, err)
+		assert.Nil(t, q)
+
+		q, err = questions.FindOneWithAnswers(ctx, 42)
+		assert.NoError(t, err)
+		assert.Nil(t, q)
+
+		q, err = questions.FindOneDeleted(ctx, 42)
+		assert.NoError(t, err)
+		assert.Nil(t, q)
+
+		tag, err := questions.FindTag(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, tag)
+
+		rev, err := questions.FindRevision(ctx, 42, 1)
+		assert.NoError(t, err)
+		assert.Nil(t, rev)
+	})
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		questions, _ := newStorages(t)
+
+		created := createQuestion(t, questions, "how?", "sql", "go")
+		require.NotZero(t, created.ID)
+		assert.Equal(t, []string{"go", "sql"}, question.TagNames(created.Tags))
+
+		q, err := questions.FindOne(ctx, created.ID)
+		require.NoError(t, err)
+		require.NotNil(t, q)
+		assert.Equal(t, "how?", q.Text)
+		assert.Equal(t, question.StatusOpen, q.Status)
+		assert.Equal(t, []string{"go", "sql"}, question.TagNames(q.Tags))
+		assert.Zero(t, q.AnswerCount)
+		assert.True(t, q.LastActivityAt.Equal(q.CreatedAt))
+
+		revisions, err := questions.FindRevisions(ctx, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, "how?", revisions[0].Text)
+		assert.Equal(t, []string{"go", "sql"}, revisions[0].Tags)
+
+		_, err = questions.Update(ctx, q, "bob", nil)
+		require.NoError(t, err)
+		revisions, err = questions.FindRevisions(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1, "nothing changed")
+
+		q.Text = "how exactly?"
+		q.AnswerPolicy = answer.PolicyMultiple
+		tags := []string{"go", "pgx"}
+		updated, err := questions.Update(ctx, q, "bob", &tags)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "pgx"}, question.TagNames(updated.Tags))
+		revisions, err = questions.FindRevisions(ctx, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, "bob", revisions[1].AuthorID)
+		assert.Equal(t, "how exactly?", revisions[1].Text)
+		assert.Equal(t, []string{"go", "pgx"}, revisions[1].Tags)
+
+		q, err = questions.FindOne(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "how exactly?", q.Text)
+		assert.Equal(t, answer.PolicyMultiple, q.AnswerPolicy)
+		assert.Equal(t, []string{"go", "pgx"}, question.TagNames(q.Tags))
+
+		tags = nil
+		_, err = questions.Update(ctx, q, "carol", &tags)
+		require.NoError(t, err)
+		revisions, err = questions.FindRevisions(ctx, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 3, "tags alone are a revision")
+		assert.Empty(t, revisions[2].Tags)
+
+		rev, err := questions.FindRevision(ctx, created.ID+1, revisions[0].ID)
+		assert.NoError(t, err)
+		assert.Nil(t, rev, "revision of another question")
+	})
+
+	t.Run("FindAllOrder", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		first := createQuestion(t, questions, "first", "go")
+		second := createQuestion(t, questions, "second", "go", "sql")
+		third := createQuestion(t, questions, "third")
+		createAnswer(t, answers, first.ID, "bob")
+		createAnswer(t, answers, first.ID, "carol")
+		createAnswer(t, answers, third.ID, "bob")
+
+		list, err := questions.FindAll(ctx, question.ListFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{third.ID, second.ID, first.ID}, questionIDs(list), "newest first by default")
+
+		list, err = questions.FindAll(ctx, question.ListFilter{Limit: 2, Order: question.OrderAsc})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID, second.ID}, questionIDs(list))
+
+		list, err = questions.FindAll(ctx, question.ListFilter{
+			Limit: 10,
+			Order: question.OrderAsc,
+			After: &question.Position{Time: list[1].CreatedAt, ID: list[1].ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{third.ID}, questionIDs(list), "keyset cursor")
+
+		list, err = questions.FindAll(ctx, question.ListFilter{Limit: 10, Sort: question.SortAnswerCount})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID, third.ID, second.ID}, questionIDs(list))
+		assert.Equal(t, []int64{2, 1, 0}, []int64{list[0].AnswerCount, list[1].AnswerCount, list[2].AnswerCount})
+
+		list, err = questions.FindAll(ctx, question.ListFilter{
+			Limit: 10,
+			Sort:  question.SortAnswerCount,
+			After: &question.Position{Count: 1, ID: third.ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{second.ID}, questionIDs(list))
+
+		list, err = questions.FindAll(ctx, question.ListFilter{Limit: 10, Sort: question.SortLastActivity})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{third.ID, first.ID, second.ID}, questionIDs(list))
+	})
+
+	t.Run("FindAllFilters", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		first := createQuestion(t, questions, "first", "go")
+		second := createQuestion(t, questions, "second", "go", "sql")
+		third := createQuestion(t, questions, "third")
+		a := createAnswer(t, answers, first.ID, "bob")
+		createAnswer(t, answers, third.ID, "bob")
+		require.NoError(t, questions.SetAcceptedAnswer(ctx, first.ID, &a.ID))
+
+		yes, no := true, false
+		for name, tc := range map[string]struct {
+			filter question.ListFilter
+			want   []uint
+		}{
+			"all tags":   {question.ListFilter{Tags: []string{"go", "sql"}}, []uint{second.ID}},
+			"any tag":    {question.ListFilter{Tags: []string{"go", "sql"}, TagMode: question.TagModeAny}, []uint{second.ID, first.ID}},
+			"solved":     {question.ListFilter{Solved: &yes}, []uint{first.ID}},
+			"unsolved":   {question.ListFilter{Solved: &no}, []uint{third.ID, second.ID}},
+			"answered":   {question.ListFilter{HasAnswers: &yes}, []uint{third.ID, first.ID}},
+			"unanswered": {question.ListFilter{HasAnswers: &no}, []uint{second.ID}},
+			"after":      {question.ListFilter{CreatedAfter: &first.CreatedAt}, []uint{third.ID, second.ID}},
+			"before":     {question.ListFilter{CreatedBefore: &second.CreatedAt}, []uint{first.ID}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				tc.filter.Limit = 10
+				list, err := questions.FindAll(ctx, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, questionIDs(list))
+			})
+		}
+	})
+
+	t.Run("DeleteCascadesToAnswers", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		early := createAnswer(t, answers, q.ID, "bob")
+		kept := createAnswer(t, answers, q.ID, "carol")
+		require.NoError(t, answers.Delete(ctx, early.ID))
+		// Keeps the two deletions apart at microsecond precision.
+		time.Sleep(2 * time.Millisecond)
+
+		require.NoError(t, questions.Delete(ctx, q.ID))
+
+		got, err := questions.FindOne(ctx, q.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+		deleted, err := questions.FindOneDeleted(ctx, q.ID)
+		require.NoError(t, err)
+		require.NotNil(t, deleted)
+
+		a, err := answers.FindOne(ctx, kept.ID)
+		require.NoError(t, err)
+		assert.Nil(t, a, "answers go with the question")
+
+		require.NoError(t, questions.Restore(ctx, q.ID))
+
+		list, err := answers.FindByQuestion(ctx, q.ID, answer.ListFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{kept.ID}, answerIDs(list), "only answers deleted with the question come back")
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		first := createQuestion(t, questions, "first")
+		second := createQuestion(t, questions, "second")
+		createQuestion(t, questions, "live")
+		a := createAnswer(t, answers, first.ID, "bob")
+		require.NoError(t, questions.Delete(ctx, first.ID))
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, questions.Delete(ctx, second.ID))
+
+		list, err := questions.FindDeleted(ctx, trash.Filter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{second.ID, first.ID}, questionIDs(list), "recently deleted first")
+
+		list, err = questions.FindDeleted(ctx, trash.Filter{
+			Limit: 10,
+			After: &trash.Position{DeletedAt: list[0].DeletedAt.Time, ID: list[0].ID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{first.ID}, questionIDs(list))
+
+		n, err := questions.Purge(ctx, list[0].DeletedAt.Time.Add(time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		gone, err := questions.FindOneDeleted(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Nil(t, gone)
+		gone2, err := answers.FindOneDeleted(ctx, a.ID)
+		require.NoError(t, err)
+		assert.Nil(t, gone2, "answers are purged with the question")
+	})
+
+	t.Run("AcceptedAnswer", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		other := createQuestion(t, questions, "other")
+		a := createAnswer(t, answers, q.ID, "bob")
+
+		assert.ErrorIs(t, questions.SetAcceptedAnswer(ctx, other.ID, &a.ID), question.ErrAnswerNotOnQuestion)
+		require.NoError(t, questions.SetAcceptedAnswer(ctx, q.ID, &a.ID))
+
+		got, err := questions.FindOneWithAnswers(ctx, q.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.AcceptedAnswer)
+		assert.Equal(t, a.ID, got.AcceptedAnswer.ID)
+		require.Len(t, got.Answers, 1)
+		assert.True(t, got.Answers[0].Accepted)
+
+		require.NoError(t, questions.SetAcceptedAnswer(ctx, q.ID, nil))
+		got, err = questions.FindOne(ctx, q.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.AcceptedAnswerID)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		questions, _ := newStorages(t)
+
+		createQuestion(t, questions, "first", "go", "golang")
+		createQuestion(t, questions, "second", "go")
+		deleted := createQuestion(t, questions, "deleted", "golang")
+		require.NoError(t, questions.Delete(ctx, deleted.ID))
+
+		usage, err := questions.FindTags(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []question.TagUsage{{Name: "go", Count: 2}, {Name: "golang", Count: 1}}, usage)
+
+		from, err := questions.FindTag(ctx, "golang")
+		require.NoError(t, err)
+		into, err := questions.FindTag(ctx, "go")
+		require.NoError(t, err)
+		require.NoError(t, questions.MergeTags(ctx, from.ID, into.ID))
+
+		usage, err = questions.FindTags(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []question.TagUsage{{Name: "go", Count: 2}}, usage)
+
+		require.NoError(t, questions.RenameTag(ctx, into.ID, "go-lang"))
+		renamed, err := questions.FindTag(ctx, "go-lang")
+		require.NoError(t, err)
+		require.NotNil(t, renamed)
+		assert.Equal(t, into.ID, renamed.ID)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		questions, answers := newStorages(t)
+
+		q := createQuestion(t, questions, "q")
+		change := &question.StatusChange{
+			QuestionID: q.ID,
+			From:       question.StatusOpen,
+			To:         question.StatusClosed,
+			ActorID:    "alice",
+			ActorRole:  auth.RoleModerator,
+			Reason:     "duplicate",
+		}
+		require.NoError(t, questions.ChangeStatus(ctx, change))
+
+		stale := &question.StatusChange{QuestionID: q.ID, From: question.StatusOpen, To: question.StatusLocked}
+		assert.ErrorIs(t, questions.ChangeStatus(ctx, stale), question.ErrInvalidTransition)
+
+		active := createQuestion(t, questions, "active")
+		createAnswer(t, answers, active.ID, "bob")
+		locked := createQuestion(t, questions, "locked")
+		require.NoError(t, questions.ChangeStatus(ctx, &question.StatusChange{
+			QuestionID: locked.ID, From: question.StatusOpen, To: question.StatusLocked,
+		}))
+
+		n, err := questions.ArchiveInactive(ctx, []question.Status{question.StatusOpen, question.StatusClosed}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n, "open and closed questions, locked ones are left")
+
+		history, err := questions.FindStatusChanges(ctx, q.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, question.StatusClosed, history[0].To)
+		assert.Equal(t, "duplicate", history[0].Reason)
+		assert.Equal(t, auth.RoleModerator, history[0].ActorRole)
+		assert.Equal(t, question.StatusClosed, history[1].From)
+		assert.Equal(t, question.StatusArchived, history[1].To)
+		assert.Equal(t, question.ArchiveReason, history[1].Reason)
+
+		got, err := questions.FindOne(ctx, locked.ID)
+		require.NoError(t, err)
+		assert.Equal(t, question.StatusLocked, got.Status)
+	})
+}
@@ -0,0 +1,35 @@
+package storagetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"testTask/migrations"
+	"testTask/pkg/client/sqlite"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SQLite returns a gorm handle on a fresh, fully migrated database file in
+// a temporary directory. It needs no server, so unlike Postgres it runs
+// with every `go test`.
+func SQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	client, err := sqlite.NewClient(ctx, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := client.DB.DB()
+	require.NoError(t, err)
+
+	provider, err := migrations.NewProvider(goose.DialectSQLite3, conn)
+	require.NoError(t, err)
+	_, err = provider.Up(ctx)
+	require.NoError(t, err)
+
+	return client.DB.Session(&gorm.Session{Logger: logger.Discard})
+}
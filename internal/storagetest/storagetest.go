@@ -0,0 +1,65 @@
+// Package storagetest checks implementations of question.Storage and
+// answer.Storage against the behaviour the services rely on. Every
+// backend runs the same suites from its own tests:
+//
+//	func TestStorage(t *testing.T) {
+//		storagetest.TestQuestionStorage(t, newStorages)
+//		storagetest.TestAnswerStorage(t, newStorages)
+//	}
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"testTask/internal/answer"
+	"testTask/internal/question"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns empty storages over one backend, so that questions and
+// answers see each other as they do in production. It is called once per
+// subtest.
+type Factory func(t *testing.T) (question.Storage, answer.Storage)
+
+var ctx = context.Background()
+
+func createQuestion(t *testing.T, s question.Storage, text string, tags ...string) *question.Question {
+	t.Helper()
+	q := &question.Question{
+		Text:         text,
+		AuthorID:     "alice",
+		Status:       question.StatusOpen,
+		AnswerPolicy: answer.DefaultPolicy,
+	}
+	for _, name := range tags {
+		q.Tags = append(q.Tags, question.Tag{Name: name})
+	}
+	created, err := s.Create(ctx, q)
+	require.NoError(t, err)
+	return created
+}
+
+func createAnswer(t *testing.T, s answer.Storage, questionID uint, userID string) *answer.Answer {
+	t.Helper()
+	a, err := s.Create(ctx, &answer.Answer{QuestionID: questionID, UserID: userID, Text: "answer by " + userID, Exclusive: true})
+	require.NoError(t, err)
+	return a
+}
+
+func questionIDs(list []question.Question) []uint {
+	ids := make([]uint, 0, len(list))
+	for _, q := range list {
+		ids = append(ids, q.ID)
+	}
+	return ids
+}
+
+func answerIDs(list []answer.Answer) []uint {
+	ids := make([]uint, 0, len(list))
+	for _, a := range list {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"testTask/pkg/gormhook"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// Plugin is a gorm plugin opening a client span for every statement.
+// Install it with db.Use(tracing.Plugin{}).
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tracing"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	return gormhook.Register(db, "tracing", startSpan, endSpan)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// Statements outside of a traced call would each start a
+			// trace of their own.
+			return
+		}
+
+		_, span := Start(ctx, "db."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system.name", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// endSpan names the span after the table as well, which gorm only knows
+// once the statement is built.
+func endSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		defer span.End()
+
+		if table := db.Statement.Table; table != "" {
+			span.SetName("db." + operation + " " + table)
+			span.SetAttributes(attribute.String("db.collection.name", table))
+		}
+		span.SetAttributes(
+			attribute.String("db.query.text", Sanitize(db.Statement.SQL.String())),
+			attribute.Int64("db.response.returned_rows", db.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			Fail(span, db.Error)
+		}
+	}
+}
+
+var (
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// Digits right after a word character or "$" belong to a name or a
+	// placeholder.
+	numberLiteral = regexp.MustCompile(`(^|[^$\w.])\d+(?:\.\d+)?\b`)
+	whitespace    = regexp.MustCompile(`\s+`)
+)
+
+// Sanitize strips the literals out of a statement so that no user data
+// ends up in traces. Bound values are never part of the SQL text, this
+// covers values inlined by raw queries.
+func Sanitize(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	sql = numberLiteral.ReplaceAllString(sql, "${1}?")
+	return strings.TrimSpace(whitespace.ReplaceAllString(sql, " "))
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"net/http"
+
+	"testTask/internal/handlers"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware opens a server span for every request, continuing the trace
+// of an incoming traceparent header. Spans are named after the mux pattern
+// so that all requests to a route share one name.
+func Middleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := handlers.Route(mux, r)
+			name := r.Method
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx, span := Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			rec := handlers.NewResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", rec.Status()))
+			if rec.Status() >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rec.Status()))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package tracing sets up OpenTelemetry and traces HTTP requests, service
+// calls and database statements.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation = "testTask"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects the span exporter. The OTLP exporter takes its endpoint
+// and headers from the standard OTEL_EXPORTER_OTLP_* variables.
+type Config struct {
+	Exporter    string
+	ServiceName string
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", cfg.ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("trace resource: %w", err)
+	}
+
+	provider := NewProvider(exporter,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider batches spans into exporter. Tests pass an in-memory
+// exporter and install the provider with otel.SetTracerProvider.
+func NewProvider(exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithBatcher(exporter)}, opts...)...)
+}
+
+// Start opens a span named name as a child of the span in ctx. Services
+// call it on entry and end the span with their named error result:
+//
+//	ctx, span := tracing.Start(ctx, "question.Create")
+//	defer tracing.End(span, &err)
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, opts...)
+}
+
+// Fail marks span as failed with err.
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End ends span, marking it failed first when *err is set. It is meant to
+// be deferred, so that it sees the error the function returns.
+func End(span trace.Span, err *error) {
+	if *err != nil {
+		Fail(span, *err)
+	}
+	span.End()
+}
@@ -0,0 +1,169 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// record installs a provider exporting into memory and returns a function
+// flushing it and returning the spans ended so far, by name.
+func record(t *testing.T) func() map[string]tracetest.SpanStub {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return func() map[string]tracetest.SpanStub {
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		spans := make(map[string]tracetest.SpanStub)
+		for _, s := range exporter.GetSpans() {
+			spans[s.Name] = s
+		}
+		return spans
+	}
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := sql.Open("pgx", "postgres://localhost/none")
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(Plugin{}))
+	return db
+}
+
+func attr(s tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range s.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestSpanStructure(t *testing.T) {
+	spans := record(t)
+	db := dryRunDB(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /widgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := Start(r.Context(), "widget.Get")
+		defer span.End()
+		db.WithContext(ctx).Where("name = ?", "secret").Find(&[]widget{})
+	})
+	h := Middleware(mux)(mux)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/widgets/7", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	got := spans()
+	require.Len(t, got, 3)
+	server, service, query := got["GET /widgets/{id}"], got["widget.Get"], got["db.query widgets"]
+
+	assert.Equal(t, traceID, server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "/widgets/{id}", attr(server, "http.route").AsString())
+	assert.Equal(t, int64(http.StatusOK), attr(server, "http.response.status_code").AsInt64())
+
+	assert.Equal(t, server.SpanContext.SpanID(), service.Parent.SpanID())
+	assert.Equal(t, service.SpanContext.SpanID(), query.Parent.SpanID())
+
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+	assert.Equal(t, "widgets", attr(query, "db.collection.name").AsString())
+	assert.Equal(t, `SELECT * FROM "widgets" WHERE name = $1`, attr(query, "db.query.text").AsString())
+}
+
+func TestMiddleware_ServerErrorFailsSpan(t *testing.T) {
+	spans := record(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /boom", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	Middleware(mux)(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	span := spans()["GET /boom"]
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.False(t, span.Parent.IsValid())
+}
+
+func TestEnd_FailsSpanOnError(t *testing.T) {
+	spans := record(t)
+	boom := errors.New("boom")
+
+	call := func(name string, fail bool) (err error) {
+		_, span := Start(context.Background(), name)
+		defer End(span, &err)
+		if fail {
+			return fmt.Errorf("widget.Get: %w", boom)
+		}
+		return nil
+	}
+	require.Error(t, call("widget.Fail", true))
+	require.NoError(t, call("widget.OK", false))
+
+	got := spans()
+	failed := got["widget.Fail"]
+	assert.Equal(t, codes.Error, failed.Status.Code)
+	assert.Equal(t, "widget.Get: boom", failed.Status.Description)
+	require.Len(t, failed.Events, 1)
+	assert.Equal(t, "exception", failed.Events[0].Name)
+
+	assert.Equal(t, codes.Unset, got["widget.OK"].Status.Code)
+	assert.Empty(t, got["widget.OK"].Events)
+}
+
+func TestPlugin_SkipsUntracedStatements(t *testing.T) {
+	spans := record(t)
+
+	dryRunDB(t).Find(&[]widget{})
+
+	assert.Empty(t, spans())
+}
+
+func TestSanitize(t *testing.T) {
+	for in, want := range map[string]string{
+		"SELECT * FROM t WHERE id = $1 LIMIT $2":           "SELECT * FROM t WHERE id = $1 LIMIT $2",
+		"SELECT * FROM t WHERE name = 'it''s' AND n = 4.5": "SELECT * FROM t WHERE name = ? AND n = ?",
+		"SELECT col1 FROM t2\n\tWHERE x IN (1,2)":          "SELECT col1 FROM t2 WHERE x IN (?,?)",
+	} {
+		assert.Equal(t, want, Sanitize(in))
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package trash
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"testTask/internal/pagination"
+)
+
+const cursorKey = "deleted_at:desc"
+
+// Params asks for a page of a trash listing.
+type Params struct {
+	Limit  int
+	Cursor string
+}
+
+// Filter is the resolved form of Params handed to storages: soft-deleted
+// rows, most recently deleted first, that come after After.
+type Filter struct {
+	Limit int
+	After *Position
+}
+
+// Position is a decoded trash cursor: the deleted_at and the id of the
+// last row of the previous page.
+type Position struct {
+	DeletedAt time.Time
+	ID        uint
+}
+
+// ParseParams reads the limit and cursor query parameters.
+func ParseParams(q url.Values) (Params, error) {
+	p := Params{Cursor: q.Get("cursor")}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Params{}, pagination.ErrInvalidLimit
+		}
+		p.Limit = n
+	}
+	return p, nil
+}
+
+// Resolve checks p and returns the filter for its page. The filter asks
+// for one row more than the page holds, so that NewPage can tell whether
+// there is a next one.
+func Resolve(p Params) (Filter, error) {
+	limit, err := pagination.Limit(p.Limit)
+	if err != nil {
+		return Filter{}, err
+	}
+	f := Filter{Limit: limit + 1}
+
+	c, err := pagination.Decode(p.Cursor, cursorKey)
+	if err != nil {
+		return Filter{}, err
+	}
+	if c != nil {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return Filter{}, pagination.ErrInvalidCursor
+		}
+		f.After = &Position{DeletedAt: t, ID: c.ID}
+	}
+	return f, nil
+}
+
+// NewPage trims the extra row list was fetched with under f and, if there
+// was one, issues a cursor pointing at the last row kept.
+func NewPage[T any](list []T, f Filter, position func(T) Position) *pagination.Page[T] {
+	limit := f.Limit - 1
+	page := &pagination.Page[T]{Items: list}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(list) > limit {
+		page.Items = list[:limit]
+		last := position(page.Items[limit-1])
+		page.NextCursor = pagination.Cursor{
+			Key:   cursorKey,
+			Value: last.DeletedAt.UTC().Format(time.RFC3339Nano),
+			ID:    last.ID,
+		}.Encode()
+	}
+	return page
+}
@@ -0,0 +1,41 @@
+package trash
+
+import (
+	"testing"
+	"time"
+
+	"testTask/internal/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPage_CursorResolvesToLastRow(t *testing.T) {
+	f, err := Resolve(Params{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, Filter{Limit: 3}, f)
+
+	deleted := time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)
+	rows := []Position{
+		{DeletedAt: deleted.Add(2 * time.Hour), ID: 3},
+		{DeletedAt: deleted.Add(time.Hour), ID: 2},
+		{DeletedAt: deleted, ID: 1},
+	}
+	page := NewPage(rows, f, func(p Position) Position { return p })
+	assert.Equal(t, rows[:2], page.Items)
+	require.NotEmpty(t, page.NextCursor)
+
+	f, err = Resolve(Params{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, &rows[1], f.After)
+
+	page = NewPage(rows[2:], f, func(p Position) Position { return p })
+	assert.Equal(t, rows[2:], page.Items)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestResolve_RejectsForeignCursor(t *testing.T) {
+	cursor := pagination.Cursor{Key: "created_at:asc", Value: "x", ID: 1}.Encode()
+	_, err := Resolve(Params{Cursor: cursor})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"testTask/pkg/logging"
+)
+
+// Purgeable is a resource whose soft-deleted records can be removed for good.
+type Purgeable interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purger periodically hard-deletes records that have stayed in the trash
+// longer than the retention period.
+type Purger struct {
+	targets   map[string]Purgeable
+	retention time.Duration
+	interval  time.Duration
+	logger    *logging.Logger
+}
+
+func NewPurger(retention, interval time.Duration, logger *logging.Logger, targets map[string]Purgeable) *Purger {
+	return &Purger{
+		targets:   targets,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run purges once immediately and then on every tick until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PurgeOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) PurgeOnce(ctx context.Context) {
+	before := time.Now().UTC().Add(-p.retention)
+
+	for name, target := range p.targets {
+		n, err := target.Purge(ctx, before)
+		if err != nil {
+			p.logger.Errorf("failed to purge %s: %v", name, err)
+			continue
+		}
+		if n > 0 {
+			p.logger.Infof("purged %d %s deleted before %s", n, name, before.Format(time.RFC3339))
+		}
+	}
+}
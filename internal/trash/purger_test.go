@@ -0,0 +1,44 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type purgeFunc func(ctx context.Context, before time.Time) (int64, error)
+
+func (f purgeFunc) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return f(ctx, before)
+}
+
+func TestPurger_PurgeOnce_UsesRetention(t *testing.T) {
+	var got []time.Time
+	record := purgeFunc(func(_ context.Context, before time.Time) (int64, error) {
+		got = append(got, before)
+		return 1, nil
+	})
+	failing := purgeFunc(func(context.Context, time.Time) (int64, error) {
+		return 0, errors.New("db is down")
+	})
+
+	p := NewPurger(48*time.Hour, time.Hour, logging.GetLogger(), map[string]Purgeable{
+		"questions": record,
+		"broken":    failing,
+		"answers":   record,
+	})
+
+	start := time.Now().UTC()
+	p.PurgeOnce(context.Background())
+
+	// A failing target must not stop the others from being purged.
+	assert.Len(t, got, 2)
+	for _, before := range got {
+		assert.WithinDuration(t, start.Add(-48*time.Hour), before, time.Second)
+	}
+}
@@ -0,0 +1,301 @@
+// Package validation checks request structs against their `validate`
+// struct tags and normalizes the text they carry.
+//
+// Supported rules, separated by commas:
+//
+//	required   the value is not zero; strings must not be blank
+//	omitempty  the remaining rules are skipped for a zero value
+//	min=N      strings have at least N characters, slices N items,
+//	           numbers are at least N
+//	max=N      the upper bound counterpart of min
+//	oneof=a b  the value, formatted, is one of the listed words
+//	dive       the rules after it apply to every slice element
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error carries every field error found in a request.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// InvalidField reports a single malformed request field.
+func InvalidField(field, message string) error {
+	return &Error{Fields: []FieldError{{Field: field, Code: "invalid", Message: message}}}
+}
+
+// Struct normalizes the strings of the struct v points to and checks its
+// `validate` tags. It returns an *Error listing every failed field, or
+// nil. Field names are taken from `json` tags. A malformed tag, such as an
+// unknown rule, is returned as a plain error and nothing is checked.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: Struct wants a pointer to a struct, got %T", v))
+	}
+
+	if err := checkTags(rv.Elem().Type()); err != nil {
+		return err
+	}
+
+	normalize(rv.Elem())
+
+	var errs []FieldError
+	checkStruct(rv.Elem(), "", &errs)
+	if len(errs) > 0 {
+		return &Error{Fields: errs}
+	}
+	return nil
+}
+
+// normalize puts every string reachable from v into Unicode NFC so that
+// visually equal input compares, counts and stores the same way.
+func normalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(norm.NFC.String(v.String()))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				normalize(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+	}
+}
+
+// tagChecks caches the outcome of checkTags per struct type.
+var tagChecks sync.Map // reflect.Type -> error
+
+// checkTags parses the `validate` tags of t and the structs it embeds by
+// value or pointer once, so that checkRule can trust them.
+func checkTags(t reflect.Type) error {
+	if err, ok := tagChecks.Load(t); ok {
+		err, _ := err.(error)
+		return err
+	}
+	err := checkStructTags(t, map[reflect.Type]bool{})
+	tagChecks.Store(t, err)
+	return err
+}
+
+func checkStructTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || fieldName(f) == "-" {
+			continue
+		}
+
+		if tag := f.Tag.Get("validate"); tag != "" {
+			if err := checkRuleTags(f.Type, strings.Split(tag, ",")); err != nil {
+				return fmt.Errorf("validation: %s.%s: %w", t, f.Name, err)
+			}
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			if err := checkStructTags(ft, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkRuleTags(t reflect.Type, rules []string) error {
+	for i, rule := range rules {
+		rule, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch rule {
+		case "required", "omitempty":
+		case "dive":
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return fmt.Errorf("dive does not apply to %s", t.Kind())
+			}
+			return checkRuleTags(t.Elem(), rules[i+1:])
+		case "min", "max":
+			if _, err := strconv.ParseInt(arg, 10, 64); err != nil {
+				return fmt.Errorf("bad %s argument %q", rule, arg)
+			}
+			elem := t
+			if elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if _, _, ok := measure(reflect.Zero(elem)); !ok {
+				return fmt.Errorf("%s does not apply to %s", rule, elem.Kind())
+			}
+		case "oneof":
+			if len(strings.Fields(arg)) == 0 {
+				return fmt.Errorf("oneof lists no values")
+			}
+		default:
+			return fmt.Errorf("unknown rule %q", rule)
+		}
+	}
+	return nil
+}
+
+func checkStruct(v reflect.Value, prefix string, errs *[]FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if tag := f.Tag.Get("validate"); tag != "" {
+			checkValue(fv, name, strings.Split(tag, ","), errs)
+		}
+
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			checkStruct(fv, name, errs)
+		}
+	}
+}
+
+func checkValue(v reflect.Value, name string, rules []string, errs *[]FieldError) {
+	for i, rule := range rules {
+		rule, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if rule == "omitempty" {
+			if v.IsZero() {
+				return
+			}
+			continue
+		}
+
+		if rule == "dive" {
+			if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+				for j := 0; j < v.Len(); j++ {
+					checkValue(v.Index(j), fmt.Sprintf("%s[%d]", name, j), rules[i+1:], errs)
+				}
+			}
+			return
+		}
+
+		if fe := checkRule(v, rule, arg); fe != nil {
+			fe.Field = name
+			*errs = append(*errs, *fe)
+			// Later rules say nothing useful about a missing value.
+			if rule == "required" {
+				return
+			}
+		}
+	}
+}
+
+// checkRule applies a single rule. The tags were parsed by checkTags, so
+// rule is known and its argument well-formed.
+func checkRule(v reflect.Value, rule, arg string) *FieldError {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if rule == "required" {
+				return &FieldError{Code: rule, Message: "is required"}
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch rule {
+	case "required":
+		if v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "" || v.IsZero() {
+			return &FieldError{Code: rule, Message: "is required"}
+		}
+	case "min", "max":
+		n, _ := strconv.ParseInt(arg, 10, 64)
+		size, unit, _ := measure(v)
+		if rule == "min" && size < n {
+			return &FieldError{Code: rule, Message: fmt.Sprintf("must be at least %d%s", n, unit)}
+		}
+		if rule == "max" && size > n {
+			return &FieldError{Code: rule, Message: fmt.Sprintf("must be at most %d%s", n, unit)}
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		got := fmt.Sprint(v.Interface())
+		for _, o := range options {
+			if got == o {
+				return nil
+			}
+		}
+		return &FieldError{Code: rule, Message: "must be one of: " + strings.Join(options, ", ")}
+	}
+	return nil
+}
+
+// measure returns what min and max compare against for v.
+func measure(v reflect.Value) (size int64, unit string, ok bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return int64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), "", true
+	}
+	return 0, "", false
+}
+
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
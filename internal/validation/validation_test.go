@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	Title string   `json:"title" validate:"required,max=5"`
+	Kind  int      `json:"kind" validate:"oneof=1 -1"`
+	Tags  []string `json:"tags" validate:"max=2,dive,min=2"`
+	Note  *string  `json:"note" validate:"required"`
+	Inner struct {
+		Name string `json:"name" validate:"required"`
+	} `json:"inner"`
+}
+
+func TestStruct_ReportsEveryField(t *testing.T) {
+	req := &request{
+		Title: "   ",
+		Kind:  3,
+		Tags:  []string{"a", "ok", "b"},
+	}
+
+	err := Struct(req)
+
+	var ve *Error
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, []FieldError{
+		{Field: "title", Code: "required", Message: "is required"},
+		{Field: "kind", Code: "oneof", Message: "must be one of: 1, -1"},
+		{Field: "tags", Code: "max", Message: "must be at most 2 items"},
+		{Field: "tags[0]", Code: "min", Message: "must be at least 2 characters"},
+		{Field: "tags[2]", Code: "min", Message: "must be at least 2 characters"},
+		{Field: "note", Code: "required", Message: "is required"},
+		{Field: "inner.name", Code: "required", Message: "is required"},
+	}, ve.Fields)
+}
+
+func TestStruct_OK(t *testing.T) {
+	note := "n"
+	req := &request{Title: "hello", Kind: -1, Tags: []string{"go"}, Note: &note}
+	req.Inner.Name = "x"
+
+	assert.NoError(t, Struct(req))
+}
+
+func TestStruct_CountsCharactersNotBytes(t *testing.T) {
+	note := "n"
+	req := &request{Title: "привет", Kind: 1, Note: &note}
+	req.Inner.Name = "x"
+
+	var ve *Error
+	require.True(t, errors.As(Struct(req), &ve))
+	assert.Equal(t, "max", ve.Fields[0].Code)
+
+	req.Title = "приве"
+	assert.NoError(t, Struct(req))
+}
+
+func TestStruct_NormalizesToNFC(t *testing.T) {
+	// "é" written as "e" followed by a combining acute accent.
+	decomposed := "cafe\u0301"
+	note := decomposed
+	req := &request{Title: decomposed, Kind: 1, Tags: []string{decomposed}, Note: &note}
+	req.Inner.Name = "x"
+
+	require.NoError(t, Struct(req))
+	assert.Equal(t, "caf\u00e9", req.Title)
+	assert.Equal(t, "caf\u00e9", req.Tags[0])
+	assert.Equal(t, "caf\u00e9", *req.Note)
+	assert.Equal(t, 4, len([]rune(req.Title)))
+	assert.False(t, strings.Contains(req.Title, "\u0301"))
+}
+
+func TestStruct_OmitEmpty(t *testing.T) {
+	var req struct {
+		Mode string `json:"mode" validate:"omitempty,oneof=a b"`
+	}
+	assert.NoError(t, Struct(&req))
+
+	req.Mode = "c"
+	var ve *Error
+	require.True(t, errors.As(Struct(&req), &ve))
+	assert.Equal(t, "oneof", ve.Fields[0].Code)
+}
+
+func TestStruct_MalformedTag(t *testing.T) {
+	var typo struct {
+		Text string `json:"text" validate:"requried"`
+	}
+	err := Struct(&typo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown rule "requried"`)
+	var ve *Error
+	assert.False(t, errors.As(err, &ve), "a bad tag is not the client's fault")
+
+	var badArg struct {
+		Tags []string `json:"tags" validate:"dive,max=x"`
+	}
+	assert.ErrorContains(t, Struct(&badArg), `bad max argument "x"`)
+
+	var wrongKind struct {
+		On bool `json:"on" validate:"min=1"`
+	}
+	assert.ErrorContains(t, Struct(&wrongKind), "min does not apply to bool")
+}
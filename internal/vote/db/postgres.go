@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testTask/internal/vote"
+	"testTask/pkg/logging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// table describes where the votes of a target kind and its score live.
+type table struct {
+	target string
+	votes  string
+	column string
+}
+
+var tables = map[vote.Target]table{
+	vote.TargetQuestion: {target: "questions", votes: "question_votes", column: "question_id"},
+	vote.TargetAnswer:   {target: "answers", votes: "answer_votes", column: "answer_id"},
+}
+
+type repository struct {
+	db     *gorm.DB
+	logger *logging.Logger
+}
+
+func NewStorage(db *gorm.DB, logger *logging.Logger) vote.Storage {
+	return &repository{db: db, logger: logger}
+}
+
+func (r *repository) Cast(ctx context.Context, target vote.Target, targetID uint, userID string, value int) (int64, error) {
+	t, ok := tables[target]
+	if !ok {
+		return 0, fmt.Errorf("unknown vote target %q", target)
+	}
+
+	var score int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the target row serializes voters on it, so reading the
+		// previous vote and applying the delta cannot interleave.
+		var row struct{ Score int64 }
+		if err := tx.Table(t.target).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("score").
+			Where("id = ? AND deleted_at IS NULL", targetID).
+			Take(&row).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return vote.ErrNotFound
+			}
+			return err
+		}
+		score = row.Score
+
+		var prev struct{ Value int }
+		err := tx.Table(t.votes).
+			Select("value").
+			Where(t.column+" = ? AND user_id = ?", targetID, userID).
+			Take(&prev).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		delta := value - prev.Value
+		if delta == 0 {
+			return nil
+		}
+
+		votes := tx.Table(t.votes)
+		switch {
+		case value == 0:
+			err = tx.Exec("DELETE FROM "+t.votes+" WHERE "+t.column+" = ? AND user_id = ?", targetID, userID).Error
+		case prev.Value == 0:
+			err = votes.Create(map[string]any{
+				t.column:  targetID,
+				"user_id": userID,
+				"value":   value,
+			}).Error
+		default:
+			err = votes.Where(t.column+" = ? AND user_id = ?", targetID, userID).
+				Updates(map[string]any{"value": value, "updated_at": time.Now()}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		score += int64(delta)
+		return tx.Table(t.target).
+			Where("id = ?", targetID).
+			UpdateColumn("score", gorm.Expr("score + ?", delta)).Error
+	})
+	if err != nil {
+		if errors.Is(err, vote.ErrNotFound) {
+			return 0, err
+		}
+		r.logger.Ctx(ctx).Errorf("failed to cast vote on %s id=%d: %v", target, targetID, err)
+		return 0, fmt.Errorf("cast vote: %w", err)
+	}
+
+	return score, nil
+}
@@ -0,0 +1,80 @@
+package vote
+
+import (
+	"net/http"
+	"testTask/internal/handlers"
+	"testTask/pkg/logging"
+)
+
+// ErrorMappings tells handlers.Error how to report vote errors.
+func ErrorMappings() []handlers.ErrorMapping {
+	return []handlers.ErrorMapping{
+		{Err: ErrNotFound, Status: http.StatusNotFound, Code: "vote_target_not_found"},
+	}
+}
+
+type handler struct {
+	logger  *logging.Logger
+	service Service
+}
+
+func NewHandler(logger *logging.Logger, service Service) handlers.Handler {
+	return &handler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *handler) Register(router *http.ServeMux) {
+	router.HandleFunc("POST /questions/{id}/vote", h.vote(TargetQuestion))
+	router.HandleFunc("DELETE /questions/{id}/vote", h.retract(TargetQuestion))
+	router.HandleFunc("POST /answers/{id}/vote", h.vote(TargetAnswer))
+	router.HandleFunc("DELETE /answers/{id}/vote", h.retract(TargetAnswer))
+}
+
+func (h *handler) vote(target Target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := handlers.PathID(r, "id")
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		var req CastVoteRequest
+		if err := handlers.ReadJSON(r, &req); err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				h.logger.Ctx(r.Context()).Warnf("failed to close request body: %v", err)
+			}
+		}()
+
+		res, err := h.service.Vote(r.Context(), target, id, &req)
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		handlers.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+func (h *handler) retract(target Target) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := handlers.PathID(r, "id")
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		res, err := h.service.Retract(r.Context(), target, id)
+		if err != nil {
+			handlers.Error(w, r, err)
+			return
+		}
+
+		handlers.WriteJSON(w, http.StatusOK, res)
+	}
+}
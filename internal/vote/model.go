@@ -0,0 +1,26 @@
+package vote
+
+type Target string
+
+const (
+	TargetQuestion Target = "question"
+	TargetAnswer   Target = "answer"
+)
+
+const (
+	Up   = 1
+	Down = -1
+)
+
+type CastVoteRequest struct {
+	Value int `json:"value" validate:"required,oneof=1 -1"`
+}
+
+// Result is the state of a target after a vote was cast or retracted.
+// Vote is the caller's current vote, 0 when there is none.
+type Result struct {
+	Target   Target `json:"target"`
+	TargetID uint   `json:"target_id"`
+	Score    int64  `json:"score"`
+	Vote     int    `json:"vote"`
+}
@@ -0,0 +1,72 @@
+package vote
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"testTask/internal/auth"
+	"testTask/internal/metrics"
+	"testTask/internal/tracing"
+	"testTask/internal/validation"
+	"testTask/pkg/logging"
+)
+
+var ErrNotFound = errors.New("vote target not found")
+
+type Service interface {
+	Vote(ctx context.Context, target Target, targetID uint, req *CastVoteRequest) (*Result, error)
+	Retract(ctx context.Context, target Target, targetID uint) (*Result, error)
+}
+
+type service struct {
+	storage Storage
+	logger  *logging.Logger
+}
+
+func NewService(storage Storage, logger *logging.Logger) Service {
+	return &service{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s *service) Vote(ctx context.Context, target Target, targetID uint, req *CastVoteRequest) (_ *Result, err error) {
+	ctx, span := tracing.Start(ctx, "vote.Vote")
+	defer tracing.End(span, &err)
+
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	return s.cast(ctx, target, targetID, req.Value)
+}
+
+func (s *service) Retract(ctx context.Context, target Target, targetID uint) (_ *Result, err error) {
+	ctx, span := tracing.Start(ctx, "vote.Retract")
+	defer tracing.End(span, &err)
+
+	return s.cast(ctx, target, targetID, 0)
+}
+
+func (s *service) cast(ctx context.Context, target Target, targetID uint, value int) (*Result, error) {
+	voter, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	score, err := s.storage.Cast(ctx, target, targetID, voter.UserID, value)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Ctx(ctx).Errorf("failed to cast vote on %s id=%d: %v", target, targetID, err)
+		}
+		return nil, err
+	}
+	metrics.VotesCast.WithLabelValues(string(target), strconv.Itoa(value)).Inc()
+
+	return &Result{
+		Target:   target,
+		TargetID: targetID,
+		Score:    score,
+		Vote:     value,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package vote
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"testTask/internal/auth"
+	"testTask/internal/validation"
+	"testTask/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	mock.Mock
+}
+
+func (m *mockStorage) Cast(ctx context.Context, target Target, targetID uint, userID string, value int) (int64, error) {
+	args := m.Called(ctx, target, targetID, userID, value)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newTestService(t *testing.T) (*service, *mockStorage) {
+	t.Helper()
+
+	storage := &mockStorage{}
+	return &service{storage: storage, logger: logging.GetLogger()}, storage
+}
+
+func withUser(id string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: id, Role: auth.RoleUser})
+}
+
+func TestService_Vote_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("Cast", mock.Anything, TargetAnswer, uint(5), "voter", Down).
+		Return(int64(-1), nil)
+
+	res, err := svc.Vote(withUser("voter"), TargetAnswer, 5, &CastVoteRequest{Value: Down})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Target: TargetAnswer, TargetID: 5, Score: -1, Vote: Down}, res)
+
+	storage.AssertExpectations(t)
+}
+
+func TestService_Vote_InvalidValue(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	_, err := svc.Vote(withUser("voter"), TargetQuestion, 1, &CastVoteRequest{Value: 2})
+
+	var ve *validation.Error
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, "value", ve.Fields[0].Field)
+	assert.Equal(t, "oneof", ve.Fields[0].Code)
+	storage.AssertNotCalled(t, "Cast", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Vote_Unauthenticated(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	_, err := svc.Vote(context.Background(), TargetQuestion, 1, &CastVoteRequest{Value: Up})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+	storage.AssertNotCalled(t, "Cast", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Vote_NotFound(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("Cast", mock.Anything, TargetQuestion, uint(9), "voter", Up).
+		Return(int64(0), ErrNotFound)
+
+	res, err := svc.Vote(withUser("voter"), TargetQuestion, 9, &CastVoteRequest{Value: Up})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, res)
+}
+
+func TestService_Retract_OK(t *testing.T) {
+	svc, storage := newTestService(t)
+
+	storage.
+		On("Cast", mock.Anything, TargetQuestion, uint(3), "voter", 0).
+		Return(int64(4), nil)
+
+	res, err := svc.Retract(withUser("voter"), TargetQuestion, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.Score)
+	assert.Equal(t, 0, res.Vote)
+
+	storage.AssertExpectations(t)
+}
@@ -0,0 +1,11 @@
+package vote
+
+import "context"
+
+type Storage interface {
+	// Cast sets the user's vote on a live target to value, or removes it
+	// when value is 0, and adjusts the target's score in the same
+	// transaction. It returns the new score, or ErrNotFound if the target
+	// does not exist or is deleted.
+	Cast(ctx context.Context, target Target, targetID uint, userID string, value int) (int64, error)
+}
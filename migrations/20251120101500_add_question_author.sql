@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN author_id VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions DROP COLUMN IF EXISTS author_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions
+    ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', text)) STORED;
+
+ALTER TABLE answers
+    ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', text)) STORED;
+
+CREATE INDEX idx_questions_search_vector ON questions USING GIN (search_vector);
+CREATE INDEX idx_answers_search_vector ON answers USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_answers_search_vector;
+DROP INDEX IF EXISTS idx_questions_search_vector;
+
+ALTER TABLE answers DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE questions DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
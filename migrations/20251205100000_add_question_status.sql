@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'open';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS question_status_changes (
+    id          SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    from_status VARCHAR(16) NOT NULL,
+    to_status   VARCHAR(16) NOT NULL,
+    actor_id    VARCHAR(64) NOT NULL DEFAULT '',
+    reason      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_question_status_changes_question_id ON question_status_changes (question_id);
+
+ALTER TABLE questions
+    ADD CONSTRAINT chk_questions_status
+    CHECK (status IN ('open', 'closed', 'locked', 'archived'));
+
+CREATE INDEX idx_questions_status ON questions (status) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_questions_status;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS chk_questions_status;
+DROP TABLE IF EXISTS question_status_changes;
+-- +goose StatementEnd
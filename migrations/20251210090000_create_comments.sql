@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comments (
+    id          SERIAL PRIMARY KEY,
+    question_id INTEGER REFERENCES questions(id) ON DELETE CASCADE,
+    answer_id   INTEGER REFERENCES answers(id) ON DELETE CASCADE,
+    user_id     VARCHAR(64) NOT NULL,
+    text        TEXT NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMP NULL,
+    CONSTRAINT chk_comments_target CHECK (num_nonnulls(question_id, answer_id) = 1)
+);
+
+CREATE INDEX idx_comments_question_id ON comments (question_id, created_at, id);
+CREATE INDEX idx_comments_answer_id ON comments (answer_id, created_at, id);
+CREATE INDEX idx_comments_deleted_at ON comments (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comments;
+-- +goose StatementEnd
@@ -0,0 +1,77 @@
+// Package migrations embeds the goose migrations into the binary: the
+// Postgres ones in this directory and their SQLite variants, with the
+// same versions, in sqlite/.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed *.sql
+var postgresFS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// FS returns the migrations for dialect, which must be goose.DialectPostgres
+// or goose.DialectSQLite3.
+func FS(dialect goose.Dialect) (fs.FS, error) {
+	switch dialect {
+	case goose.DialectPostgres:
+		return postgresFS, nil
+	case goose.DialectSQLite3:
+		return fs.Sub(sqliteFS, "sqlite")
+	default:
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+}
+
+// NewProvider returns a goose provider applying the embedded migrations of
+// dialect to db. On Postgres runs take an advisory lock, so replicas
+// started together with --auto-migrate apply each migration once.
+func NewProvider(dialect goose.Dialect, db *sql.DB) (*goose.Provider, error) {
+	fsys, err := FS(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []goose.ProviderOption
+	if dialect == goose.DialectPostgres {
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, fmt.Errorf("migrations lock: %w", err)
+		}
+		opts = append(opts, goose.WithSessionLocker(locker))
+	}
+
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("migrations provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Version returns the version of the newest migration, the schema version
+// this build expects. Both dialects share it.
+func Version() (int64, error) {
+	names, err := fs.Glob(postgresFS, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range names {
+		v, err := goose.NumericComponent(name)
+		if err != nil {
+			return 0, err
+		}
+		latest = max(latest, v)
+	}
+	return latest, nil
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"testTask/pkg/client/sqlite"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versions(t *testing.T, dialect goose.Dialect) []int64 {
+	t.Helper()
+
+	fsys, err := FS(dialect)
+	require.NoError(t, err)
+	names, err := fs.Glob(fsys, "*.sql")
+	require.NoError(t, err)
+
+	list := make([]int64, 0, len(names))
+	for _, name := range names {
+		v, err := goose.NumericComponent(name)
+		require.NoError(t, err)
+		list = append(list, v)
+	}
+	return list
+}
+
+func TestDialectsShareVersions(t *testing.T) {
+	pg := versions(t, goose.DialectPostgres)
+	require.NotEmpty(t, pg)
+	assert.Equal(t, pg, versions(t, goose.DialectSQLite3))
+
+	latest, err := Version()
+	require.NoError(t, err)
+	assert.Equal(t, pg[len(pg)-1], latest)
+
+	_, err = FS(goose.DialectMySQL)
+	assert.Error(t, err)
+}
+
+func TestSQLiteUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	client, err := sqlite.NewClient(ctx, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	db, err := client.DB.DB()
+	require.NoError(t, err)
+
+	provider, err := NewProvider(goose.DialectSQLite3, db)
+	require.NoError(t, err)
+
+	_, err = provider.Up(ctx)
+	require.NoError(t, err)
+	current, target, err := provider.GetVersions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, target, current)
+
+	_, err = provider.DownTo(ctx, 0)
+	require.NoError(t, err, "every down migration applies")
+	current, err = provider.GetDBVersion(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, current)
+
+	_, err = provider.Up(ctx)
+	assert.NoError(t, err, "and the schema comes back")
+}
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite only adds columns with constant defaults; the backfill below
+-- replaces the placeholder.
+ALTER TABLE questions ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE answers ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE questions SET updated_at = created_at;
+UPDATE answers SET updated_at = created_at;
+
+CREATE TABLE question_revisions (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id  INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    text         TEXT NOT NULL,
+    author_id    VARCHAR(64) NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_question_revisions_question_id ON question_revisions (question_id, id);
+
+CREATE TABLE answer_revisions (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    answer_id   INTEGER NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    text        TEXT NOT NULL,
+    author_id   VARCHAR(64) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_answer_revisions_answer_id ON answer_revisions (answer_id, id);
+
+INSERT INTO question_revisions (question_id, text, created_at)
+SELECT id, text, created_at FROM questions;
+
+INSERT INTO answer_revisions (answer_id, text, author_id, created_at)
+SELECT id, text, user_id, created_at FROM answers;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS answer_revisions;
+DROP TABLE IF EXISTS question_revisions;
+
+ALTER TABLE answers DROP COLUMN updated_at;
+ALTER TABLE questions DROP COLUMN updated_at;
+-- +goose StatementEnd
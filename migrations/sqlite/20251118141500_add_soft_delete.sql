@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE answers ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX idx_questions_deleted_at ON questions (deleted_at);
+CREATE INDEX idx_answers_deleted_at ON answers (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_answers_deleted_at;
+DROP INDEX IF EXISTS idx_questions_deleted_at;
+
+ALTER TABLE answers DROP COLUMN deleted_at;
+ALTER TABLE questions DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- FTS5 tables index the text of questions and answers without copying it
+-- (external content). Triggers keep them in step with the base tables.
+CREATE VIRTUAL TABLE questions_fts USING fts5(text, content='questions', content_rowid='id');
+CREATE VIRTUAL TABLE answers_fts USING fts5(text, content='answers', content_rowid='id');
+
+CREATE TRIGGER questions_fts_insert AFTER INSERT ON questions BEGIN
+    INSERT INTO questions_fts (rowid, text) VALUES (new.id, new.text);
+END;
+CREATE TRIGGER questions_fts_delete AFTER DELETE ON questions BEGIN
+    INSERT INTO questions_fts (questions_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+CREATE TRIGGER questions_fts_update AFTER UPDATE OF text ON questions BEGIN
+    INSERT INTO questions_fts (questions_fts, rowid, text) VALUES ('delete', old.id, old.text);
+    INSERT INTO questions_fts (rowid, text) VALUES (new.id, new.text);
+END;
+
+CREATE TRIGGER answers_fts_insert AFTER INSERT ON answers BEGIN
+    INSERT INTO answers_fts (rowid, text) VALUES (new.id, new.text);
+END;
+CREATE TRIGGER answers_fts_delete AFTER DELETE ON answers BEGIN
+    INSERT INTO answers_fts (answers_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+CREATE TRIGGER answers_fts_update AFTER UPDATE OF text ON answers BEGIN
+    INSERT INTO answers_fts (answers_fts, rowid, text) VALUES ('delete', old.id, old.text);
+    INSERT INTO answers_fts (rowid, text) VALUES (new.id, new.text);
+END;
+
+INSERT INTO questions_fts (questions_fts) VALUES ('rebuild');
+INSERT INTO answers_fts (answers_fts) VALUES ('rebuild');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS answers_fts_update;
+DROP TRIGGER IF EXISTS answers_fts_delete;
+DROP TRIGGER IF EXISTS answers_fts_insert;
+DROP TRIGGER IF EXISTS questions_fts_update;
+DROP TRIGGER IF EXISTS questions_fts_delete;
+DROP TRIGGER IF EXISTS questions_fts_insert;
+
+DROP TABLE IF EXISTS answers_fts;
+DROP TABLE IF EXISTS questions_fts;
+-- +goose StatementEnd
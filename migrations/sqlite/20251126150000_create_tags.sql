@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tags (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        VARCHAR(32) NOT NULL UNIQUE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE question_tags (
+    question_id  INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    tag_id       INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (question_id, tag_id)
+);
+
+CREATE INDEX idx_question_tags_tag_id ON question_tags (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS question_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE answers ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE question_votes (
+    question_id  INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id      VARCHAR(64) NOT NULL,
+    value        SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (question_id, user_id)
+);
+
+CREATE TABLE answer_votes (
+    answer_id   INTEGER NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    user_id     VARCHAR(64) NOT NULL,
+    value       SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (answer_id, user_id)
+);
+
+CREATE INDEX idx_answers_question_id_score ON answers (question_id, score DESC, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_answers_question_id_score;
+DROP TABLE IF EXISTS answer_votes;
+DROP TABLE IF EXISTS question_votes;
+ALTER TABLE answers DROP COLUMN score;
+ALTER TABLE questions DROP COLUMN score;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite cannot drop a column that is part of a foreign key, so instead of
+-- REFERENCES ... ON DELETE SET NULL a trigger clears the accepted answer.
+ALTER TABLE questions ADD COLUMN accepted_answer_id INTEGER NULL;
+
+CREATE INDEX idx_questions_accepted_answer_id ON questions (accepted_answer_id);
+
+CREATE TRIGGER answers_withdraw_acceptance AFTER DELETE ON answers BEGIN
+    UPDATE questions SET accepted_answer_id = NULL WHERE accepted_answer_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS answers_withdraw_acceptance;
+DROP INDEX IF EXISTS idx_questions_accepted_answer_id;
+ALTER TABLE questions DROP COLUMN accepted_answer_id;
+-- +goose StatementEnd
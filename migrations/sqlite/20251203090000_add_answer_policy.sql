@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions
+    ADD COLUMN answer_policy VARCHAR(16) NOT NULL DEFAULT 'single_editable';
+
+-- Answers given under a single-answer policy are exclusive: a user holds at
+-- most one live exclusive answer per question. Duplicates that slipped in
+-- before the index existed keep only the oldest answer exclusive.
+ALTER TABLE answers
+    ADD COLUMN exclusive BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE answers SET exclusive = FALSE
+WHERE deleted_at IS NULL
+  AND id NOT IN (
+      SELECT MIN(id) FROM answers
+      WHERE deleted_at IS NULL
+      GROUP BY question_id, user_id
+  );
+
+CREATE UNIQUE INDEX idx_answers_question_id_user_id
+    ON answers (question_id, user_id)
+    WHERE deleted_at IS NULL AND exclusive;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_answers_question_id_user_id;
+ALTER TABLE answers DROP COLUMN exclusive;
+ALTER TABLE questions DROP COLUMN answer_policy;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite cannot add a constraint to an existing table, so the check that
+-- Postgres gets in create_question_status_changes lives on the column.
+ALTER TABLE questions
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'open'
+    CONSTRAINT chk_questions_status CHECK (status IN ('open', 'closed', 'locked', 'archived'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions DROP COLUMN status;
+-- +goose StatementEnd
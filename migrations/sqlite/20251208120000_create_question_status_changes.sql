@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS question_status_changes (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    from_status VARCHAR(16) NOT NULL,
+    to_status   VARCHAR(16) NOT NULL,
+    actor_id    VARCHAR(64) NOT NULL DEFAULT '',
+    reason      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_question_status_changes_question_id ON question_status_changes (question_id);
+
+CREATE INDEX idx_questions_status ON questions (status) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_questions_status;
+DROP TABLE IF EXISTS question_status_changes;
+-- +goose StatementEnd
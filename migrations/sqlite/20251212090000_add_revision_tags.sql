@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tag names of the question at the revision, as a JSON array. Revisions
+-- written before tags were recorded keep NULL.
+ALTER TABLE question_revisions ADD COLUMN tags TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE question_revisions DROP COLUMN tags;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Role the actor had when making the change, so that a question closed by
+-- a moderator cannot be reopened by its author. Older changes keep ''.
+ALTER TABLE question_status_changes ADD COLUMN actor_role VARCHAR(16) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE question_status_changes DROP COLUMN actor_role;
+-- +goose StatementEnd
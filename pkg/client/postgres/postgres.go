@@ -14,7 +14,9 @@ type Client struct {
 }
 
 func NewClient(ctx context.Context, dsn string) (*Client, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns driver errors such as unique violations into
+	// gorm.ErrDuplicatedKey, so repositories stay dialect-neutral.
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("gorm open: %w", err)
 	}
@@ -31,6 +33,15 @@ func NewClient(ctx context.Context, dsn string) (*Client, error) {
 	return &Client{DB: db}, nil
 }
 
+// Ping checks the database is reachable within timeout.
+func (c *Client) Ping(ctx context.Context, timeout time.Duration) error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return pingWithTimeout(ctx, sqlDB, timeout)
+}
+
 func (c *Client) Close() error {
 	sqlDB, err := c.DB.DB()
 	if err != nil {
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Client is a SQLite database in a single file, opened through a pure-Go
+// driver so the binary still builds without cgo.
+type Client struct {
+	DB *gorm.DB
+}
+
+func NewClient(ctx context.Context, path string) (*Client, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create database dir: %w", err)
+	}
+
+	// Foreign keys are off by default in SQLite. WAL lets readers run
+	// alongside the single writer, and immediate transactions take the
+	// write lock up front instead of failing with SQLITE_BUSY halfway.
+	dsn := path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "journal_mode(WAL)"},
+		"_txlock": {"immediate"},
+	}.Encode()
+
+	// TranslateError maps constraint errors to gorm.ErrDuplicatedKey and
+	// gorm.ErrForeignKeyViolated, the same errors the Postgres client gives.
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		NowFunc:        func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gorm open: %w", err)
+	}
+	if err := db.Use(utcPlugin{}); err != nil {
+		return nil, fmt.Errorf("utc plugin: %w", err)
+	}
+
+	client := &Client{DB: db}
+	if err := client.Ping(ctx, 5*time.Second); err != nil {
+		return nil, fmt.Errorf("db ping: %w", err)
+	}
+
+	return client, nil
+}
+
+// Ping checks the database file can be queried within timeout.
+func (c *Client) Ping(ctx context.Context, timeout time.Duration) error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	ctxPing, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return sqlDB.PingContext(ctxPing)
+}
+
+func (c *Client) Close() error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
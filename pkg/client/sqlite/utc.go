@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"testTask/pkg/gormhook"
+
+	"gorm.io/gorm"
+)
+
+// utcPlugin converts time arguments to UTC before they reach the driver.
+// SQLite keeps timestamps as text and compares them as strings, which
+// only orders correctly when every value carries the same offset.
+type utcPlugin struct{}
+
+func (utcPlugin) Name() string {
+	return "sqlite:utc"
+}
+
+func (utcPlugin) Initialize(db *gorm.DB) error {
+	return gormhook.Register(db, "sqlite:utc", gormhook.Always(wrapConnPool), gormhook.Always(unwrapConnPool))
+}
+
+// wrapConnPool routes the statement through utcConnPool. gorm only builds
+// the SQL and its arguments in the main callback, so the arguments cannot
+// be rewritten here directly. The wrapper goes on after the implicit
+// transaction has begun and comes off before it commits, since gorm finds
+// the transaction by the type of the pool.
+func wrapConnPool(db *gorm.DB) {
+	if _, ok := db.Statement.ConnPool.(utcConnPool); !ok {
+		db.Statement.ConnPool = utcConnPool{db.Statement.ConnPool}
+	}
+}
+
+func unwrapConnPool(db *gorm.DB) {
+	if p, ok := db.Statement.ConnPool.(utcConnPool); ok {
+		db.Statement.ConnPool = p.ConnPool
+	}
+}
+
+type utcConnPool struct {
+	gorm.ConnPool
+}
+
+func (p utcConnPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query, toUTC(args)...)
+}
+
+func (p utcConnPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query, toUTC(args)...)
+}
+
+func (p utcConnPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query, toUTC(args)...)
+}
+
+func toUTC(args []any) []any {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			args[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				args[i] = v.UTC()
+			}
+		case gorm.DeletedAt:
+			if v.Valid {
+				args[i] = v.Time.UTC()
+			}
+		case sql.NullTime:
+			if v.Valid {
+				args[i] = v.Time.UTC()
+			}
+		}
+	}
+	return args
+}
@@ -0,0 +1,47 @@
+// Package gormhook registers gorm callbacks around every statement.
+package gormhook
+
+import "gorm.io/gorm"
+
+const (
+	begin  = "gorm:begin_transaction"
+	commit = "gorm:commit_or_rollback_transaction"
+)
+
+// Register installs before(operation) and after(operation) around the main
+// callback of each of the create, query, update, delete, row and raw
+// processors, as name+":before_"+operation and
+// name+":after_"+operation. Writes run in a transaction gorm opens for
+// them, and the hooks go inside it, so that they wrap the statement alone.
+func Register(db *gorm.DB, name string, before, after func(operation string) func(*gorm.DB)) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().After(begin).Before("gorm:create").Register, cb.Create().After("gorm:create").Before(commit).Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().After(begin).Before("gorm:update").Register, cb.Update().After("gorm:update").Before(commit).Register},
+		{"delete", cb.Delete().After(begin).Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Before(commit).Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before(name+":before_"+h.operation, before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after(name+":after_"+h.operation, after(h.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Always adapts a callback that does not depend on the operation.
+func Always(fn func(*gorm.DB)) func(operation string) func(*gorm.DB) {
+	return func(string) func(*gorm.DB) {
+		return fn
+	}
+}
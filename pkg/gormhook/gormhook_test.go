@@ -0,0 +1,46 @@
+package gormhook
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestRegister(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&widget{}))
+
+	var calls []string
+	hook := func(when string) func(string) func(*gorm.DB) {
+		return func(operation string) func(*gorm.DB) {
+			return func(db *gorm.DB) {
+				call := when
+				if _, ok := db.Statement.ConnPool.(*sql.Tx); ok {
+					call += " in tx"
+				}
+				calls = append(calls, call+" "+operation)
+			}
+		}
+	}
+	require.NoError(t, Register(db, "test", hook("before"), hook("after")))
+
+	require.NoError(t, db.Create(&widget{Name: "a"}).Error)
+	require.NoError(t, db.Find(&[]widget{}).Error)
+
+	assert.Equal(t, []string{
+		"before in tx create", "after in tx create",
+		"before query", "after query",
+	}, calls)
+	assert.NotNil(t, db.Callback().Create().Get("test:before_create"))
+	assert.NotNil(t, db.Callback().Raw().Get("test:after_raw"))
+}
@@ -1,75 +1,183 @@
 package logging
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path"
 	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-type writerHook struct {
-	Writer    []io.Writer
-	LogLevels []logrus.Level
-}
+type Fields = logrus.Fields
 
-func (h *writerHook) Fire(entry *logrus.Entry) error {
-	line, err := entry.String()
-	if err != nil {
-		return err
-	}
+const (
+	FormatJSON = "json"
+	FormatText = "text"
 
-	for _, w := range h.Writer {
-		w.Write([]byte(line))
-	}
-	return err
-}
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
 
-func (h *writerHook) Levels() []logrus.Level {
-	return h.LogLevels
+// Config describes where and how the logger writes. The zero value logs
+// text at info level to stdout.
+type Config struct {
+	Format  string
+	Level   string
+	Outputs []string
+
+	// File is used when Outputs contains "file". The file is rotated once
+	// it reaches MaxSizeMB, rotated files are removed after MaxAge or once
+	// there are more than MaxBackups of them; zero disables either limit.
+	File       string
+	MaxSizeMB  int
+	MaxAge     time.Duration
+	MaxBackups int
 }
 
-var entry *logrus.Entry
-
 type Logger struct {
 	*logrus.Entry
+	closers []io.Closer
 }
 
+var defaultLogger atomic.Pointer[Logger]
+
+// GetLogger returns the process-wide logger set by SetDefault, or a plain
+// stdout logger when none was configured, as in unit tests.
 func GetLogger() *Logger {
-	return &Logger{entry}
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l, _ := New(Config{})
+	defaultLogger.CompareAndSwap(nil, l)
+	return defaultLogger.Load()
 }
 
-func init() {
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// New builds a logger from cfg. Close releases the files it opened.
+func New(cfg Config) (*Logger, error) {
 	l := logrus.New()
 	l.SetReportCaller(true)
-	l.SetFormatter(&logrus.TextFormatter{
-		CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-			filename := path.Base(f.File)
-			return fmt.Sprintf("%s()", f.Function), fmt.Sprintf("%s:%d", filename, f.Line)
-		},
-		DisableColors: false,
-		FullTimestamp: true,
-	})
-
-	if err := os.MkdirAll("./logs", 0755); err != nil {
-		panic(err)
+
+	prettyfier := func(f *runtime.Frame) (string, string) {
+		return fmt.Sprintf("%s()", f.Function), fmt.Sprintf("%s:%d", path.Base(f.File), f.Line)
+	}
+	switch cfg.Format {
+	case FormatJSON:
+		l.SetFormatter(&logrus.JSONFormatter{
+			CallerPrettyfier: prettyfier,
+			TimestampFormat:  time.RFC3339Nano,
+		})
+	case FormatText, "":
+		l.SetFormatter(&logrus.TextFormatter{
+			CallerPrettyfier: prettyfier,
+			FullTimestamp:    true,
+		})
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	level := logrus.InfoLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(cfg.Level); err != nil {
+			return nil, fmt.Errorf("unknown log level %q", cfg.Level)
+		}
+	}
+	l.SetLevel(level)
+
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputStdout}
 	}
 
-	fullFile, err := os.OpenFile("./logs/full.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to open log file: %v", err))
+	logger := &Logger{Entry: logrus.NewEntry(l)}
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, out := range outputs {
+		switch strings.TrimSpace(out) {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputStderr:
+			writers = append(writers, os.Stderr)
+		case OutputFile:
+			if cfg.File == "" {
+				return nil, errors.New("log output \"file\" needs a file path")
+			}
+			file := &lumberjack.Logger{
+				Filename:   cfg.File,
+				MaxSize:    cfg.MaxSizeMB,
+				MaxAge:     days(cfg.MaxAge),
+				MaxBackups: cfg.MaxBackups,
+			}
+			writers = append(writers, file)
+			logger.closers = append(logger.closers, file)
+		default:
+			return nil, fmt.Errorf("unknown log output %q", out)
+		}
 	}
+	l.SetOutput(io.MultiWriter(writers...))
+
+	return logger, nil
+}
 
-	l.SetOutput(io.Discard)
+// days rounds up, as lumberjack keeps rotated files for whole days.
+func days(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Hours() / 24))
+}
 
-	l.AddHook(&writerHook{
-		Writer:    []io.Writer{fullFile, os.Stdout},
-		LogLevels: logrus.AllLevels,
-	})
+func (l *Logger) Close() error {
+	var errs []error
+	for _, c := range l.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
 
-	l.SetLevel(logrus.TraceLevel)
+// With returns a child logger that adds fields to every entry.
+func (l *Logger) With(fields Fields) *Logger {
+	return &Logger{Entry: l.Entry.WithFields(fields)}
+}
 
-	entry = logrus.NewEntry(l)
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx, or the default one.
+func FromContext(ctx context.Context) *Logger {
+	return GetLogger().Ctx(ctx)
+}
+
+// Ctx returns the request-scoped logger of ctx, falling back to l outside
+// of requests, e.g. in background jobs. Entries carry the trace and span
+// IDs of the span active in ctx, so logs can be matched with traces.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if cl, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		l = cl
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return l.With(Fields{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+	}
+	return l
 }
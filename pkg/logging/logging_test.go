@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"format":    {Format: "xml"},
+		"level":     {Level: "loud"},
+		"output":    {Outputs: []string{"syslog"}},
+		"file path": {Outputs: []string{OutputFile}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_JSONFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "app.log")
+	l, err := New(Config{
+		Format:  FormatJSON,
+		Level:   "warn",
+		Outputs: []string{OutputFile},
+		File:    file,
+		MaxAge:  36 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	l.Info("dropped")
+	l.With(Fields{"request_id": "r1"}).Warn("kept")
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "kept", entry["msg"])
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "r1", entry["request_id"])
+	assert.Contains(t, entry["file"], "logging_test.go")
+}
+
+func TestContext(t *testing.T) {
+	root, err := New(Config{})
+	require.NoError(t, err)
+	child := root.With(Fields{"request_id": "r1"})
+
+	assert.Same(t, root, root.Ctx(context.Background()))
+	assert.Same(t, child, root.Ctx(NewContext(context.Background(), child)))
+	assert.Same(t, GetLogger(), FromContext(context.Background()))
+}
+
+func TestCtx_TraceIDs(t *testing.T) {
+	root, err := New(Config{})
+	require.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3},
+		SpanID:  trace.SpanID{4, 5, 6},
+	})
+	l := root.Ctx(trace.ContextWithSpanContext(context.Background(), sc))
+
+	assert.Equal(t, sc.TraceID().String(), l.Data["trace_id"])
+	assert.Equal(t, sc.SpanID().String(), l.Data["span_id"])
+}
+
+func TestDays(t *testing.T) {
+	assert.Equal(t, 0, days(0))
+	assert.Equal(t, 1, days(time.Hour))
+	assert.Equal(t, 7, days(7*24*time.Hour))
+}